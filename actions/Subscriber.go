@@ -11,7 +11,7 @@ type Subscriber struct {
 }
 
 func (s *Subscriber) OnTick(tick core.Ticker) b3.Status {
-	value := s.GetValueFromAncestor("subClient", tick.Blackboard())
+	value := core.AncestorValue(tick, s, "subClient")
 	if value == nil {
 		return b3.FAILURE
 	}
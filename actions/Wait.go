@@ -2,7 +2,6 @@ package actions
 
 import (
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 	"time"
 )
@@ -16,24 +15,13 @@ import (
 **/
 type Wait struct {
 	core.Action
-	waitTime int64
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (w *Wait) Initialize(setting *config.BTNodeCfg) {
-	w.Action.Initialize(setting)
-	w.waitTime = setting.GetPropertyAsInt64("milliseconds")
+	/**
+	 * Time to wait, in milliseconds.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} milliseconds
+	**/
+	Milliseconds core.Value `b3:"milliseconds,required"`
 }
 
 /**
@@ -43,6 +31,10 @@ func (w *Wait) Initialize(setting *config.BTNodeCfg) {
  * @return {Constant} A state constant.
 **/
 func (w *Wait) OnTick(tick core.Ticker) b3.Status {
-	time.Sleep(time.Duration(w.waitTime) * time.Millisecond)
+	milliseconds, err := w.Milliseconds.Number(tick)
+	if err != nil {
+		return b3.ERROR
+	}
+	time.Sleep(time.Duration(milliseconds * float64(time.Millisecond)))
 	return b3.SUCCESS
 }
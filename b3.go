@@ -1,8 +1,10 @@
 package behavior3go
 
+import "strconv"
+
 // b3 define
 const (
-	VERSION = "0.2.0"
+	VERSION = "0.4.0"
 
 	// Node categories
 	COMPOSITE    = "composite"
@@ -17,6 +19,18 @@ const (
 	PRIORITY     = "Priority"
 	MEMSEQUENCE  = "MemSequence"
 	MEMPRIORITY  = "MemPriority"
+
+	RANDOMSEQUENCE         = "RandomSequence"
+	RANDOMSELECTOR         = "RandomSelector"
+	WEIGHTEDRANDOMSELECTOR = "WeightedRandomSelector"
+	UTILITYSELECTOR        = "UtilitySelector"
+	REACTIVESEQUENCE       = "ReactiveSequence"
+	REACTIVEFALLBACK       = "ReactiveFallback"
+	SWITCH                 = "Switch"
+	IFTHENELSE             = "IfThenElse"
+	WHILEDOELSE            = "WhileDoElse"
+	CONFIGURABLESEQUENCE   = "ConfigurableSequence"
+	RACE                   = "Race"
 )
 
 // Returning status
@@ -28,3 +42,17 @@ const (
 	RUNNING Status = 3
 	ERROR   Status = 4
 )
+
+func (s Status) String() string {
+	switch s {
+	case SUCCESS:
+		return "SUCCESS"
+	case FAILURE:
+		return "FAILURE"
+	case RUNNING:
+		return "RUNNING"
+	case ERROR:
+		return "ERROR"
+	}
+	return "Status(" + strconv.Itoa(int(s)) + ")"
+}
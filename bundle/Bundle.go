@@ -0,0 +1,469 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+/**
+ * A bundle puts the trees of several files together into one project. The
+ * manifest lists tree files, directories or glob patterns, relative to the
+ * manifest, and includes other bundles under a namespace:
+ *
+ *     {
+ *       "name": "orc",
+ *       "select": "main",
+ *       "files": ["trees/", "boss.bt"],
+ *       "include": [{"path": "../common/bundle.json", "namespace": "common"}]
+ *     }
+ *
+ * Every file can be a tree, a project, a raw project or a text tree. The
+ * trees of an included bundle get the namespace in front of their ID and
+ * title, so a subtree node named `common/Patrol` refers to the tree
+ * `Patrol` of the included bundle. Inside the included bundle the tree is
+ * still referred to as `Patrol`. Subtree references are rewritten to the
+ * IDs of the resolved trees.
+ *
+ * @module bundle
+**/
+
+//目录里的清单文件名
+const ManifestName = "bundle.json"
+
+type Manifest struct {
+	//被包含时默认的命名空间
+	Name string `json:"name"`
+	//选中的树,ID或标题
+	Select string `json:"select,omitempty"`
+	//树文件,目录或通配符
+	Files []string `json:"files"`
+	//包含的其他bundle
+	Include []Include `json:"include,omitempty"`
+}
+
+type Include struct {
+	//清单文件或所在的目录
+	Path string `json:"path"`
+	//命名空间,默认为bundle的名字
+	Namespace string `json:"namespace,omitempty"`
+}
+
+//同一个ID或标题的树出现在多个文件里,使用第一个
+type Conflict struct {
+	//id或title
+	Kind  string
+	Key   string
+	Files []string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("duplicate tree %s %q in %s", c.Kind, c.Key, strings.Join(c.Files, ", "))
+}
+
+//找不到的子树
+type Reference struct {
+	File   string
+	Tree   string
+	NodeID string
+	Name   string
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s: tree %s node %s: subtree %q not found", r.File, r.Tree, r.NodeID, r.Name)
+}
+
+type Result struct {
+	Project    *config.BTProjectCfg
+	Conflicts  []Conflict
+	Unresolved []Reference
+	Migrations []config.AppliedMigration
+}
+
+//加载清单和包含的bundle,path为清单文件或所在的目录
+func Load(path string) (*Result, error) {
+	return newLoader(ioutil.ReadFile).run(path)
+}
+
+func newLoader(read func(path string) ([]byte, error)) *bundleLoader {
+	return &bundleLoader{result: &Result{}, read: read}
+}
+
+func (l *bundleLoader) run(path string) (*Result, error) {
+	m, entries, err := l.load(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	l.unresolved(entries)
+	project := &config.BTProjectCfg{Version: config.LatestVersion, ID: m.Name, CustomNodes: l.customNodes}
+	for _, e := range entries {
+		project.Trees = append(project.Trees, e.tree)
+	}
+	if m.Select != "" {
+		if tree := project.FindTree(m.Select); tree != nil {
+			project.Select = tree.ID
+		} else {
+			return nil, fmt.Errorf("%s: selected tree %q not found", path, m.Select)
+		}
+	} else if len(project.Trees) > 0 {
+		project.Select = project.Trees[0].ID
+	}
+	l.result.Project = project
+	return l.result, nil
+}
+
+//读取一个树文件,所有格式都转成工程并升级到最新版本
+func LoadFile(path string) (*config.BTProjectCfg, []config.AppliedMigration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseFile(path, data)
+}
+
+func parseFile(path string, data []byte) (*config.BTProjectCfg, []config.AppliedMigration, error) {
+	var err error
+	var project *config.BTProjectCfg
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		trees, err := config.ParseTreesText(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", path, err)
+		}
+		project = &config.BTProjectCfg{Version: config.LatestVersion, Trees: trees}
+	} else {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", path, err)
+		}
+		switch {
+		case fields["data"] != nil:
+			var raw config.RawProjectCfg
+			err = json.Unmarshal(data, &raw)
+			project = &raw.Data
+		case fields["trees"] != nil:
+			project = &config.BTProjectCfg{}
+			err = json.Unmarshal(data, project)
+		default:
+			var tree config.BTTreeCfg
+			err = json.Unmarshal(data, &tree)
+			project = &config.BTProjectCfg{Version: tree.Version, Trees: []config.BTTreeCfg{tree}}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	applied, err := config.MigrateProject(project)
+	if err != nil {
+		return nil, applied, fmt.Errorf("%s: %v", path, err)
+	}
+	return project, applied, nil
+}
+
+//读取清单文件
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(path, data)
+}
+
+func parseManifest(path string, data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &m, nil
+}
+
+//bundle里的一棵树,relID和relTitle是相对于当前bundle的名字
+type entry struct {
+	tree     config.BTTreeCfg
+	file     string
+	relID    string
+	relTitle string
+	//已经解析的子树节点
+	resolved map[string]bool
+}
+
+type bundleLoader struct {
+	result      *Result
+	customNodes []config.BTCustomNodeCfg
+	//读取文件,校验时检查文件的哈希
+	read func(path string) ([]byte, error)
+	//读取过的文件
+	files []string
+}
+
+func (l *bundleLoader) readFile(path string) ([]byte, error) {
+	data, err := l.read(path)
+	if err == nil {
+		l.files = append(l.files, path)
+	}
+	return data, err
+}
+
+func (l *bundleLoader) load(path string, stack []string) (*Manifest, []*entry, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, ManifestName)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range stack {
+		if p == abs {
+			return nil, nil, fmt.Errorf("include cycle: %s > %s", strings.Join(stack, " > "), abs)
+		}
+	}
+	stack = append(stack, abs)
+	data, err := l.readFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := parseManifest(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := filepath.Dir(path)
+
+	files, err := expandFiles(dir, m.Files, abs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	var entries []*entry
+	for _, file := range files {
+		data, err := l.readFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		project, applied, err := parseFile(file, data)
+		l.result.Migrations = append(l.result.Migrations, applied...)
+		if err != nil {
+			return nil, nil, err
+		}
+		l.addCustomNodes(project.CustomNodes)
+		for _, tree := range project.Trees {
+			entries = append(entries, &entry{tree: tree, file: file, relID: tree.ID, relTitle: tree.Title, resolved: make(map[string]bool)})
+		}
+	}
+	for _, inc := range m.Include {
+		incPath := inc.Path
+		if !filepath.IsAbs(incPath) {
+			incPath = filepath.Join(dir, incPath)
+		}
+		sub, subEntries, err := l.load(incPath, stack)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", path, err)
+		}
+		ns := inc.Namespace
+		if ns == "" {
+			ns = sub.Name
+		}
+		if ns == "" {
+			return nil, nil, fmt.Errorf("%s: include %s needs a namespace", path, inc.Path)
+		}
+		for _, e := range subEntries {
+			e.addNamespace(ns)
+			entries = append(entries, e)
+		}
+	}
+
+	entries = l.removeConflicts(entries)
+	l.resolve(entries)
+	return m, entries, nil
+}
+
+//子bundle的树放到命名空间下,已解析的子树引用一起修改
+func (e *entry) addNamespace(ns string) {
+	e.relID = ns + "/" + e.relID
+	e.relTitle = ns + "/" + e.relTitle
+	e.tree.ID = ns + "/" + e.tree.ID
+	e.tree.Title = ns + "/" + e.tree.Title
+	for id, node := range e.tree.Nodes {
+		if e.resolved[id] {
+			node.Name = ns + "/" + node.Name
+			e.tree.Nodes[id] = node
+		}
+	}
+}
+
+//ID重复的树只保留第一棵,标题重复只报告
+func (l *bundleLoader) removeConflicts(entries []*entry) []*entry {
+	byID := make(map[string][]*entry)
+	byTitle := make(map[string][]*entry)
+	var result []*entry
+	for _, e := range entries {
+		if len(byID[e.relID]) == 0 {
+			result = append(result, e)
+			if e.relTitle != e.relID {
+				byTitle[e.relTitle] = append(byTitle[e.relTitle], e)
+			}
+		}
+		byID[e.relID] = append(byID[e.relID], e)
+	}
+	l.report("id", byID)
+	l.report("title", byTitle)
+	return result
+}
+
+func (l *bundleLoader) report(kind string, index map[string][]*entry) {
+	keys := make([]string, 0, len(index))
+	for k, list := range index {
+		if len(list) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := Conflict{Kind: kind, Key: k}
+		for _, e := range index[k] {
+			c.Files = append(c.Files, e.file)
+		}
+		l.result.Conflicts = append(l.result.Conflicts, c)
+	}
+}
+
+//把子树节点的名字改成找到的树的ID
+func (l *bundleLoader) resolve(entries []*entry) {
+	byID := make(map[string]*entry)
+	byTitle := make(map[string]*entry)
+	for _, e := range entries {
+		byID[e.relID] = e
+		if _, ok := byTitle[e.relTitle]; !ok {
+			byTitle[e.relTitle] = e
+		}
+	}
+	find := func(key string) *entry {
+		if e, ok := byID[key]; ok {
+			return e
+		}
+		return byTitle[key]
+	}
+	for _, e := range entries {
+		ids := make([]string, 0, len(e.tree.Nodes))
+		for id := range e.tree.Nodes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			node := e.tree.Nodes[id]
+			if node.Category != "tree" || e.resolved[id] {
+				continue
+			}
+			target := find(node.Name)
+			if target == nil {
+				target = find(node.Title)
+			}
+			if target == nil {
+				// may be found by an including bundle
+				continue
+			}
+			node.Name = target.relID
+			e.tree.Nodes[id] = node
+			e.resolved[id] = true
+		}
+	}
+}
+
+//所有bundle都加载完后还没有解析的子树
+func (l *bundleLoader) unresolved(entries []*entry) {
+	for _, e := range entries {
+		ids := make([]string, 0, len(e.tree.Nodes))
+		for id := range e.tree.Nodes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			node := e.tree.Nodes[id]
+			if node.Category == "tree" && !e.resolved[id] {
+				l.result.Unresolved = append(l.result.Unresolved, Reference{File: e.file, Tree: e.tree.Title, NodeID: id, Name: node.Name})
+			}
+		}
+	}
+}
+
+func (l *bundleLoader) addCustomNodes(nodes []config.BTCustomNodeCfg) {
+	for _, n := range nodes {
+		found := false
+		for _, old := range l.customNodes {
+			if old.Name == n.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.customNodes = append(l.customNodes, n)
+		}
+	}
+}
+
+//展开清单里的文件,目录包含下面所有的树文件
+func expandFiles(dir string, patterns []string, manifest string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{manifest: true}
+	add := func(file string) error {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			files = append(files, file)
+		}
+		return nil
+	}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", pattern)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				if err := add(match); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			err = filepath.Walk(match, func(file string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() || !isTreeFile(file) {
+					return err
+				}
+				return add(file)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+func isTreeFile(path string) bool {
+	if filepath.Base(path) == ManifestName {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".b3", ".bt":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package bundle_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/magicsea/behavior3go/bundle"
+	"github.com/magicsea/behavior3go/config"
+)
+
+//在临时目录里写文件,返回目录
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func treeIDs(project *config.BTProjectCfg) []string {
+	var ids []string
+	for _, tree := range project.Trees {
+		ids = append(ids, tree.ID)
+	}
+	return ids
+}
+
+var orcBundle = map[string]string{
+	"orc/bundle.json": `{"name": "orc", "select": "Boss",
+		"files": ["trees/", "boss.json"],
+		"include": [{"path": "../common", "namespace": "common"}]}`,
+	"orc/trees/main.bt":   "tree \"Main\" @id=main\n  Sequence @id=s\n    subtree common/Patrol @id=p\n    subtree Boss @id=b\n",
+	"orc/trees/notes.txt": "not a tree",
+	"orc/boss.json": `{"id": "boss", "title": "Boss", "root": "a", "nodes": {
+		"a": {"id": "a", "name": "Wait", "properties": {"milliseconds": 1}}}}`,
+	"common/bundle.json": `{"name": "shared", "files": ["*.bt"]}`,
+	"common/patrol.bt":   "tree \"Patrol\" @id=patrol\n  Sequence @id=s\n    subtree Walk @id=w\n",
+	"common/walk.bt":     "tree \"Walk\" @id=walk\n  Log @id=l info=walk\n",
+}
+
+func TestLoadBundle(t *testing.T) {
+	dir := writeFiles(t, orcBundle)
+	result, err := bundle.Load(filepath.Join(dir, "orc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := result.Project
+	if want := []string{"main", "boss", "common/patrol", "common/walk"}; !reflect.DeepEqual(treeIDs(project), want) {
+		t.Errorf("trees = %v, want %v", treeIDs(project), want)
+	}
+	if project.ID != "orc" || project.Select != "boss" || project.Version != config.LatestVersion {
+		t.Errorf("project %s select %s version %s", project.ID, project.Select, project.Version)
+	}
+	if len(result.Conflicts) != 0 || len(result.Unresolved) != 0 {
+		t.Errorf("conflicts %v, unresolved %v", result.Conflicts, result.Unresolved)
+	}
+	//subtree references point at the IDs of the resolved trees
+	main := project.FindTree("main")
+	if p, b := main.Nodes["p"].Name, main.Nodes["b"].Name; p != "common/patrol" || b != "boss" {
+		t.Errorf("main subtrees = %s, %s", p, b)
+	}
+	patrol := project.FindTree("common/Patrol")
+	if patrol == nil || patrol.Nodes["w"].Name != "common/walk" {
+		t.Errorf("patrol = %+v", patrol)
+	}
+	//the unversioned JSON tree was migrated
+	if len(result.Migrations) != 3 || result.Migrations[0].Tree != "Boss" {
+		t.Errorf("migrations = %v", result.Migrations)
+	}
+	if c := project.FindTree("boss").Nodes["a"].Category; c != "action" {
+		t.Errorf("boss category = %q", c)
+	}
+}
+
+func TestLoadBundleDefaultNamespace(t *testing.T) {
+	files := map[string]string{
+		"bundle.json":        `{"files": ["main.bt"], "include": [{"path": "common/bundle.json"}]}`,
+		"main.bt":            "tree \"Main\" @id=main\n  subtree shared/Walk @id=w\n",
+		"common/bundle.json": `{"name": "shared", "files": ["walk.bt"]}`,
+		"common/walk.bt":     "tree \"Walk\" @id=walk\n  Log @id=l info=walk\n",
+	}
+	result, err := bundle.Load(writeFiles(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main", "shared/walk"}; !reflect.DeepEqual(treeIDs(result.Project), want) {
+		t.Errorf("trees = %v", treeIDs(result.Project))
+	}
+	if result.Project.Select != "main" {
+		t.Errorf("select = %s", result.Project.Select)
+	}
+}
+
+func TestBundleConflicts(t *testing.T) {
+	files := map[string]string{
+		"bundle.json": `{"files": ["a.bt", "b.bt", "c.bt"]}`,
+		"a.bt":        "tree \"First\" @id=x\n  Log info=a\n",
+		"b.bt":        "tree \"Second\" @id=x\n  Log info=b\n",
+		"c.bt":        "tree \"First\" @id=y\n  subtree Missing @id=m\n",
+	}
+	dir := writeFiles(t, files)
+	result, err := bundle.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the first tree with an ID wins, duplicate titles are only reported
+	if want := []string{"x", "y"}; !reflect.DeepEqual(treeIDs(result.Project), want) {
+		t.Errorf("trees = %v", treeIDs(result.Project))
+	}
+	if info := result.Project.Trees[0].Nodes["n1"].Properties["info"]; info != "a" {
+		t.Errorf("tree x comes from %v", info)
+	}
+	want := []string{
+		`duplicate tree id "x" in ` + filepath.Join(dir, "a.bt") + ", " + filepath.Join(dir, "b.bt"),
+		`duplicate tree title "First" in ` + filepath.Join(dir, "a.bt") + ", " + filepath.Join(dir, "c.bt"),
+	}
+	var got []string
+	for _, c := range result.Conflicts {
+		got = append(got, c.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %q, want %q", got, want)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0].String() != filepath.Join(dir, "c.bt")+`: tree First node m: subtree "Missing" not found` {
+		t.Errorf("unresolved = %v", result.Unresolved)
+	}
+}
+
+func TestBundleErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"include cycle", map[string]string{
+			"bundle.json":   `{"name": "a", "files": [], "include": [{"path": "b"}]}`,
+			"b/bundle.json": `{"name": "b", "files": [], "include": [{"path": ".."}]}`,
+		}, "include cycle"},
+		{"no namespace", map[string]string{
+			"bundle.json":   `{"files": [], "include": [{"path": "b"}]}`,
+			"b/bundle.json": `{"files": []}`,
+		}, "include b needs a namespace"},
+		{"no match", map[string]string{
+			"bundle.json": `{"files": ["missing/*.bt"]}`,
+		}, "no files match"},
+		{"bad tree", map[string]string{
+			"bundle.json": `{"files": ["a.bt"]}`,
+			"a.bt":        "tree a\n  Log\n  Log\n",
+		}, "line 3: tree has more than one root node"},
+		{"bad manifest", map[string]string{
+			"bundle.json": `{"files": "a.bt"}`,
+		}, "cannot unmarshal"},
+		{"selected tree", map[string]string{
+			"bundle.json": `{"select": "none", "files": ["a.bt"]}`,
+			"a.bt":        "tree a\n  Log\n",
+		}, `selected tree "none" not found`},
+	}
+	for _, c := range cases {
+		_, err := bundle.Load(writeFiles(t, c.files))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"raw.b3": `{"name": "raw", "data": {"version": "0.4.0", "trees": [
+			{"id": "t", "title": "T", "root": "a", "nodes": {"a": {"id": "a", "name": "Log", "category": "action"}}}]}}`,
+		"text.bt": "tree \"T\" @version=0.1.0\n  Log\n",
+	})
+	project, applied, err := bundle.LoadFile(filepath.Join(dir, "raw.b3"))
+	if err != nil || len(applied) != 0 || len(project.Trees) != 1 || project.Trees[0].ID != "t" {
+		t.Errorf("raw: %v, %v, %v", project, applied, err)
+	}
+	project, applied, err = bundle.LoadFile(filepath.Join(dir, "text.bt"))
+	if err != nil || len(applied) != 3 || project.Trees[0].Version != config.LatestVersion {
+		t.Errorf("text: %v, %v, %v", project, applied, err)
+	}
+}
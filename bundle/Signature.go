@@ -0,0 +1,298 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/**
+ * A packed bundle has a `bundle.sum` file next to its manifest with the
+ * SHA-256 checksum of every file the bundle reads, the manifests of the
+ * included bundles too, and optionally an ed25519 signature of these
+ * checksums:
+ *
+ *     bundle.Pack("ai/orc", privateKey)
+ *     ...
+ *     result, err := bundle.LoadVerified("ai/orc", publicKey)
+ *
+ * `LoadVerified` refuses a bundle with a missing, changed or unlisted file,
+ * or with a bad signature, before any tree is built. The paths in
+ * `bundle.sum` are clean relative paths below the manifest directory, so a
+ * packed bundle cannot include bundles outside its directory, pack a
+ * manifest in a common parent directory instead.
+ *
+ * @class Signature
+**/
+
+//清单目录里的校验文件名
+const ChecksumName = "bundle.sum"
+
+type Checksums struct {
+	//相对于清单目录的路径和SHA-256
+	Files map[string]string `json:"files"`
+	//Digest的ed25519签名,base64编码
+	Signature string `json:"signature,omitempty"`
+}
+
+var ErrNoSignature = errors.New("bundle is not signed")
+
+//检查路径和哈希,Check通过后Digest的每一行都只有一种解析
+func (c *Checksums) Check() error {
+	for _, p := range c.paths() {
+		if err := checkPath(p); err != nil {
+			return err
+		}
+		if sum := c.Files[p]; !validChecksum(sum) {
+			return fmt.Errorf("%s: invalid checksum %q", p, sum)
+		}
+	}
+	return nil
+}
+
+//按路径排序
+func (c *Checksums) paths() []string {
+	paths := make([]string, 0, len(c.Files))
+	for p := range c.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+//签名的内容,每行一个文件的哈希和路径,按路径排序
+func (c *Checksums) Digest() []byte {
+	var b bytes.Buffer
+	for _, p := range c.paths() {
+		fmt.Fprintf(&b, "%s  %s\n", c.Files[p], p)
+	}
+	return b.Bytes()
+}
+
+func (c *Checksums) Sign(key ed25519.PrivateKey) error {
+	if err := c.Check(); err != nil {
+		return err
+	}
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, c.Digest()))
+	return nil
+}
+
+func (c *Checksums) VerifySignature(key ed25519.PublicKey) error {
+	if err := c.Check(); err != nil {
+		return err
+	}
+	if c.Signature == "" {
+		return ErrNoSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if !ed25519.Verify(key, c.Digest(), sig) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+//解析base64编码的公钥
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace([]byte(s))))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+//解析base64编码的私钥
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	data, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace([]byte(s))))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("private key must be %d bytes, got %d", ed25519.PrivateKeySize, len(data))
+	}
+	return ed25519.PrivateKey(data), nil
+}
+
+/**
+ * Computes the checksums of all the files of a bundle and writes them to
+ * `bundle.sum`. With a key the checksums are signed.
+ *
+ * @method Pack
+ * @param {String} path The manifest or its directory.
+ * @param {ed25519.PrivateKey} [key] The signing key, may be nil.
+**/
+func Pack(path string, key ed25519.PrivateKey) (*Checksums, error) {
+	manifest := manifestPath(path)
+	dir := filepath.Dir(manifest)
+	l := newLoader(ioutil.ReadFile)
+	if _, err := l.run(manifest); err != nil {
+		return nil, err
+	}
+	sums := &Checksums{Files: make(map[string]string)}
+	for _, file := range l.files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := relPath(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPath(rel); err != nil {
+			return nil, err
+		}
+		sums.Files[rel] = checksum(data)
+	}
+	if key != nil {
+		if err := sums.Sign(key); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.MarshalIndent(sums, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ChecksumName), append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+/**
+ * Checks the signature, when a key is given, and the checksum of every
+ * file listed in `bundle.sum`.
+ *
+ * @method Verify
+ * @param {String} path The manifest or its directory.
+ * @param {ed25519.PublicKey} [key] The public key, nil skips the signature.
+ * @return {Checksums} The verified checksums.
+**/
+func Verify(path string, key ed25519.PublicKey) (*Checksums, error) {
+	dir := filepath.Dir(manifestPath(path))
+	data, err := ioutil.ReadFile(filepath.Join(dir, ChecksumName))
+	if err != nil {
+		return nil, err
+	}
+	var sums Checksums
+	if err := json.Unmarshal(data, &sums); err != nil {
+		return nil, fmt.Errorf("%s: %v", ChecksumName, err)
+	}
+	if err := sums.Check(); err != nil {
+		return nil, fmt.Errorf("%s: %v", ChecksumName, err)
+	}
+	if key != nil {
+		if err := sums.VerifySignature(key); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range sums.paths() {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		if checksum(data) != sums.Files[p] {
+			return nil, fmt.Errorf("%s: checksum mismatch", p)
+		}
+	}
+	return &sums, nil
+}
+
+//校验后加载,加载时读到的文件必须在校验文件里且没有变化
+func LoadVerified(path string, key ed25519.PublicKey) (*Result, error) {
+	manifest := manifestPath(path)
+	dir := filepath.Dir(manifest)
+	sums, err := Verify(manifest, key)
+	if err != nil {
+		return nil, err
+	}
+	return newLoader(func(file string) ([]byte, error) {
+		rel, err := relPath(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		sum, ok := sums.Files[rel]
+		if !ok {
+			return nil, fmt.Errorf("%s: not listed in %s", rel, ChecksumName)
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if checksum(data) != sum {
+			return nil, fmt.Errorf("%s: checksum mismatch", rel)
+		}
+		return data, nil
+	}).run(manifest)
+}
+
+func manifestPath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, ManifestName)
+	}
+	return path
+}
+
+func relPath(dir, file string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+//bundle.sum里的路径,清单目录下用/分隔的相对路径
+func checkPath(p string) error {
+	for _, r := range p {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("path %q contains control characters", p)
+		}
+	}
+	switch {
+	case p == "":
+		return errors.New("empty path")
+	case strings.Contains(p, "\\"):
+		return fmt.Errorf("path %q must use forward slashes", p)
+	case path.IsAbs(p) || filepath.IsAbs(filepath.FromSlash(p)) || filepath.VolumeName(filepath.FromSlash(p)) != "":
+		return fmt.Errorf("path %q is absolute", p)
+	case path.Clean(p) != p:
+		return fmt.Errorf("path %q is not clean", p)
+	case p == ".." || strings.HasPrefix(p, "../"):
+		return fmt.Errorf("path %q is outside the bundle directory", p)
+	}
+	return nil
+}
+
+//小写十六进制的SHA-256
+func validChecksum(sum string) bool {
+	data, err := hex.DecodeString(sum)
+	return err == nil && len(data) == sha256.Size && hex.EncodeToString(data) == sum
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,168 @@
+package bundle_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/magicsea/behavior3go/bundle"
+)
+
+var signedBundle = map[string]string{
+	"bundle.json":        `{"name": "orc", "files": ["trees/"], "include": [{"path": "common"}]}`,
+	"trees/main.bt":      "tree \"Main\" @id=main\n  subtree common/Walk @id=w\n",
+	"common/bundle.json": `{"name": "common", "files": ["walk.bt"]}`,
+	"common/walk.bt":     "tree \"Walk\" @id=walk\n  Log @id=l info=walk\n",
+}
+
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, key
+}
+
+func writeSums(t *testing.T, dir string, sums *bundle.Checksums) {
+	t.Helper()
+	data, err := json.Marshal(sums)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, bundle.ChecksumName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPackAndLoadVerified(t *testing.T) {
+	dir := writeFiles(t, signedBundle)
+	pub, key := generateKey(t)
+	sums, err := bundle.Pack(dir, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums.Files) != 4 || sums.Signature == "" {
+		t.Fatalf("sums = %+v", sums)
+	}
+	if _, ok := sums.Files["common/walk.bt"]; !ok {
+		t.Errorf("files = %v", sums.Files)
+	}
+	result, err := bundle.LoadVerified(dir, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Project.Trees) != 2 {
+		t.Errorf("trees = %v", result.Project.Trees)
+	}
+
+	other, _ := generateKey(t)
+	if _, err := bundle.LoadVerified(dir, other); err == nil || err.Error() != "signature does not match" {
+		t.Errorf("other key: err = %v", err)
+	}
+}
+
+func TestLoadVerifiedRejectsChanges(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(dir string) error
+		err    string
+	}{
+		{"changed file", func(dir string) error {
+			return ioutil.WriteFile(filepath.Join(dir, "common/walk.bt"), []byte("tree \"Walk\" @id=walk\n  Log @id=l info=run\n"), 0644)
+		}, "common/walk.bt: checksum mismatch"},
+		{"unlisted file", func(dir string) error {
+			return ioutil.WriteFile(filepath.Join(dir, "trees/extra.bt"), []byte("tree \"Extra\"\n  Log info=x\n"), 0644)
+		}, "trees/extra.bt: not listed in bundle.sum"},
+	}
+	for _, c := range cases {
+		dir := writeFiles(t, signedBundle)
+		if _, err := bundle.Pack(dir, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.change(dir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bundle.LoadVerified(dir, nil); err == nil || err.Error() != c.err {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+}
+
+func TestUnsignedBundle(t *testing.T) {
+	dir := writeFiles(t, signedBundle)
+	if _, err := bundle.Pack(dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Verify(dir, nil); err != nil {
+		t.Errorf("checksums only: %v", err)
+	}
+	pub, _ := generateKey(t)
+	if _, err := bundle.Verify(dir, pub); err != bundle.ErrNoSignature {
+		t.Errorf("err = %v, want %v", err, bundle.ErrNoSignature)
+	}
+}
+
+func TestChecksumsCheck(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	cases := []struct {
+		path, sum, err string
+	}{
+		{"a.bt", sum, ""},
+		{"dir/a.bt", sum, ""},
+		{"a.bt\n" + sum + "  b.bt", sum, "contains control characters"},
+		{"", sum, "empty path"},
+		{`dir\a.bt`, sum, "must use forward slashes"},
+		{"/etc/passwd", sum, "is absolute"},
+		{"dir/../a.bt", sum, "is not clean"},
+		{"./a.bt", sum, "is not clean"},
+		{"../other/a.bt", sum, "outside the bundle directory"},
+		{"..", sum, "outside the bundle directory"},
+		{"a.bt", strings.ToUpper(sum), "invalid checksum"},
+		{"a.bt", sum[:62], "invalid checksum"},
+		{"a.bt", sum + "\n", "invalid checksum"},
+	}
+	for _, c := range cases {
+		err := (&bundle.Checksums{Files: map[string]string{c.path: c.sum}}).Check()
+		if c.err == "" && err != nil || c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%q %q: err = %v, want %s", c.path, c.sum, err, c.err)
+		}
+	}
+}
+
+func TestVerifyRejectsBadEntries(t *testing.T) {
+	//a signed bundle.sum with a path outside the bundle is refused before any file is read
+	dir := writeFiles(t, signedBundle)
+	pub, key := generateKey(t)
+	sums := &bundle.Checksums{Files: map[string]string{"../secret": strings.Repeat("00", 32)}}
+	if err := sums.Sign(key); err == nil {
+		t.Fatal("signed a path outside the bundle")
+	}
+	writeSums(t, dir, sums)
+	if _, err := bundle.Verify(dir, pub); err == nil || !strings.Contains(err.Error(), "outside the bundle directory") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestPackRejectsOutsideIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"orc/bundle.json":    `{"name": "orc", "files": [], "include": [{"path": "../common"}]}`,
+		"common/bundle.json": `{"name": "common", "files": ["walk.bt"]}`,
+		"common/walk.bt":     "tree \"Walk\"\n  Log info=walk\n",
+	})
+	if _, err := bundle.Pack(filepath.Join(dir, "orc"), nil); err == nil || !strings.Contains(err.Error(), "outside the bundle directory") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	a, b := strings.Repeat("0a", 32), strings.Repeat("0b", 32)
+	sums := &bundle.Checksums{Files: map[string]string{"z.bt": a, "a.bt": b}}
+	if got, want := string(sums.Digest()), b+"  a.bt\n"+a+"  z.bt\n"; got != want {
+		t.Errorf("digest = %q, want %q", got, want)
+	}
+}
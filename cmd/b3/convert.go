@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+//转换文件格式
+func runConvert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := flags.String("to", kindProject, "output format: tree, project, raw or text")
+	treeKey := flags.String("tree", "", "tree to write when the output is a single tree")
+	output := flags.String("o", "", "output file, default stdout")
+	name := flags.String("name", "", "name of a raw project, default the file name")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["convert"].usage)
+		return 2
+	}
+	doc, err := loadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 convert:", err)
+		return 2
+	}
+
+	var tree *config.BTTreeCfg
+	switch {
+	case *treeKey != "":
+		if tree, err = doc.findTree(*treeKey); err != nil {
+			fmt.Fprintln(os.Stderr, "b3 convert:", err)
+			return 2
+		}
+	case *to == kindTree && len(doc.project.Trees) == 1:
+		tree = &doc.project.Trees[0]
+	}
+	return writeOutput("convert", *output, *to, rawName(*name, doc), doc.project, tree)
+}
+
+//从工程里取出一棵树
+func runExtract(args []string) int {
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	treeKey := flags.String("tree", "", "id or title of the tree, default the selected tree")
+	to := flags.String("to", kindTree, "output format: tree or text")
+	output := flags.String("o", "", "output file, default stdout")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *to != kindTree && *to != kindText {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["extract"].usage)
+		return 2
+	}
+	doc, err := loadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 extract:", err)
+		return 2
+	}
+	key := *treeKey
+	if key == "" {
+		key = doc.project.Select
+	}
+	tree, err := doc.findTree(key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 extract:", err)
+		return 2
+	}
+	return writeOutput("extract", *output, *to, "", doc.project, tree)
+}
+
+func writeOutput(cmd, path, kind, name string, project *config.BTProjectCfg, tree *config.BTTreeCfg) int {
+	w, err := createOutput(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "b3 %s: %v\n", cmd, err)
+		return 1
+	}
+	err = writeDocument(w, kind, name, project, tree)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "b3 %s: %v\n", cmd, err)
+		return 1
+	}
+	return 0
+}
+
+func rawName(name string, doc *document) string {
+	if name != "" {
+		return name
+	}
+	if doc.name != "" {
+		return doc.name
+	}
+	base := filepath.Base(doc.path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"-to", "text", "testdata/project.json"}, 0, `tree "Main" @id=main
+  Priority @id=p
+    subtree Patrol @id=t
+    Log @id=l info=idle
+
+tree "Patrol" @id=patrol
+  Wait @id=w milliseconds=10
+`},
+		{[]string{"-to", "text", "testdata/bundle"}, 0, `tree "Attack" @id=attack
+  Wait @id=w milliseconds=5
+
+tree "Main" @id=main
+  Sequence @id=s
+    subtree attack "Attack" @id=a
+    Log @id=l info=done
+`},
+		{[]string{"-to", "text", "-tree", "patrol", "testdata/project.json"}, 0, "tree \"Patrol\" @id=patrol\n  Wait @id=w milliseconds=10\n"},
+		{[]string{"-to", "tree", "testdata/project.json"}, 1, ""},
+		{[]string{"-to", "yaml", "testdata/tree.json"}, 1, ""},
+		{[]string{"-tree", "none", "testdata/project.json"}, 2, ""},
+		{[]string{"testdata/broken.json"}, 2, ""},
+		{nil, 2, ""},
+	}
+	for _, c := range cases {
+		code, stdout, _ := runCommand(t, "convert", c.args...)
+		if code != c.code || stdout != c.stdout {
+			t.Errorf("convert %v: exit %d, output\n%s\nwant exit %d, output\n%s", c.args, code, stdout, c.code, c.stdout)
+		}
+	}
+}
+
+//转换后的文件能按新格式读回来
+func TestConvertRoundTrip(t *testing.T) {
+	cases := []struct {
+		args []string
+		kind string
+		name string
+	}{
+		{[]string{"-to", "raw", "testdata/tree.bt"}, kindRaw, "tree"},
+		{[]string{"-to", "raw", "-name", "attack", "testdata/tree.bt"}, kindRaw, "attack"},
+		{[]string{"-to", "raw", "testdata/raw.b3"}, kindRaw, "guard"},
+		{[]string{"-to", "project", "testdata/raw.b3"}, kindProject, ""},
+		{[]string{"-to", "tree", "testdata/tree.bt"}, kindTree, ""},
+		{[]string{"-to", "text", "testdata/tree.json"}, kindText, ""},
+	}
+	for i, c := range cases {
+		out := filepath.Join(t.TempDir(), "out")
+		args := append([]string{"-o", out}, c.args...)
+		if code, stdout, stderr := runCommand(t, "convert", args...); code != 0 || stdout != "" {
+			t.Errorf("convert %v: exit %d, stdout %q, stderr %q", c.args, code, stdout, stderr)
+			continue
+		}
+		src, err := loadDocument(c.args[len(c.args)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := loadDocument(out)
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+		if doc.kind != c.kind || doc.name != c.name || len(doc.project.Trees) != len(src.project.Trees) {
+			t.Errorf("convert %v: kind %s, name %q, %d trees", c.args, doc.kind, doc.name, len(doc.project.Trees))
+			continue
+		}
+		for j, tree := range doc.project.Trees {
+			want := src.project.Trees[j]
+			if tree.ID != want.ID || tree.Root != want.Root || len(tree.Nodes) != len(want.Nodes) {
+				t.Errorf("convert %v: tree %s root %s, want %s root %s", c.args, tree.ID, tree.Root, want.ID, want.Root)
+			}
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"-to", "text", "-tree", "Patrol", "testdata/project.json"}, 0, "tree \"Patrol\" @id=patrol\n  Wait @id=w milliseconds=10\n"},
+		//默认取选中的树
+		{[]string{"-to", "text", "testdata/bundle"}, 0, `tree "Main" @id=main
+  Sequence @id=s
+    subtree attack "Attack" @id=a
+    Log @id=l info=done
+`},
+		{[]string{"-tree", "none", "testdata/project.json"}, 2, ""},
+		{[]string{"-to", "raw", "testdata/project.json"}, 2, ""},
+		{[]string{"testdata/broken.bt"}, 2, ""},
+		{nil, 2, ""},
+	}
+	for _, c := range cases {
+		code, stdout, _ := runCommand(t, "extract", c.args...)
+		if code != c.code || stdout != c.stdout {
+			t.Errorf("extract %v: exit %d, output\n%s\nwant exit %d, output\n%s", c.args, code, stdout, c.code, c.stdout)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "main.json")
+	if code, _, stderr := runCommand(t, "extract", "-o", out, "testdata/project.json"); code != 0 {
+		t.Fatalf("extract: exit %d, %s", code, stderr)
+	}
+	doc, err := loadDocument(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree := doc.singleTree(); tree == nil || tree.ID != "main" || len(tree.Nodes) != 3 {
+		t.Errorf("extracted %s: %+v", doc.kind, doc.project.Trees)
+	}
+}
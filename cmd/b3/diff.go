@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/magicsea/behavior3go/diff"
+)
+
+//比较两个版本,有改动时返回1
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	treeKey := flags.String("tree", "", "only compare the tree with this id or title")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["diff"].usage)
+		return 2
+	}
+	old, err := loadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 diff:", err)
+		return 2
+	}
+	cur, err := loadDocument(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 diff:", err)
+		return 2
+	}
+
+	var changes []diff.Change
+	switch {
+	case *treeKey != "":
+		a, err := old.findTree(*treeKey)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "b3 diff:", err)
+			return 2
+		}
+		b, err := cur.findTree(*treeKey)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "b3 diff:", err)
+			return 2
+		}
+		changes = diff.DiffTrees(a, b)
+	case old.singleTree() != nil && cur.singleTree() != nil:
+		changes = diff.DiffTrees(old.singleTree(), cur.singleTree())
+	default:
+		changes = diff.DiffProjects(old.project, cur.project)
+	}
+
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	changes := `[Guard] property changed Priority > [0] Sequence > [0] Wait: milliseconds 100 -> 200
+[Guard] moved Priority > [0] Sequence > [2] Log: from "Priority > [1] Log"
+[Guard] added Priority > [1] Log
+[Guard] removed Priority > [2] Log
+`
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"testdata/diff/old.bt", "testdata/diff/new.bt"}, 1, changes},
+		{[]string{"-tree", "guard", "testdata/diff/old.bt", "testdata/diff/new.bt"}, 1, changes},
+		{[]string{"testdata/diff/old.bt", "testdata/diff/old.bt"}, 0, ""},
+		//工程按树比较
+		{[]string{"testdata/project.json", "testdata/raw.b3"}, 1, "[Guard] tree added\n[Main] tree removed\n[Patrol] tree removed\n"},
+		{[]string{"-tree", "none", "testdata/diff/old.bt", "testdata/diff/new.bt"}, 2, ""},
+		{[]string{"testdata/diff/old.bt", "testdata/broken.bt"}, 2, ""},
+		{[]string{"testdata/diff/old.bt"}, 2, ""},
+	}
+	for _, c := range cases {
+		code, stdout, _ := runCommand(t, "diff", c.args...)
+		if code != c.code || stdout != c.stdout {
+			t.Errorf("diff %v: exit %d, output\n%s\nwant exit %d, output\n%s", c.args, code, stdout, c.code, c.stdout)
+		}
+	}
+}
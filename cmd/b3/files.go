@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/bundle"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+//文件格式
+const (
+	kindTree    = "tree"
+	kindProject = "project"
+	kindRaw     = "raw"
+	kindText    = "text"
+)
+
+//加载的文件,所有格式都转成工程
+type document struct {
+	path    string
+	kind    string
+	name    string
+	project *config.BTProjectCfg
+	applied []config.AppliedMigration
+}
+
+func loadDocument(path string) (*document, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return loadBundle(path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{path: path}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		trees, err := config.ParseTreesText(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		doc.kind = kindText
+		doc.project = &config.BTProjectCfg{Version: config.LatestVersion, Trees: trees}
+		if len(trees) > 0 {
+			doc.project.Select = trees[0].ID
+		}
+	} else {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		switch {
+		case fields["files"] != nil:
+			return loadBundle(path)
+		case fields["data"] != nil:
+			var raw config.RawProjectCfg
+			if err := json.Unmarshal(data, &raw); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			doc.kind, doc.name, doc.project = kindRaw, raw.Name, &raw.Data
+		case fields["trees"] != nil:
+			var project config.BTProjectCfg
+			if err := json.Unmarshal(data, &project); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			doc.kind, doc.project = kindProject, &project
+		default:
+			var tree config.BTTreeCfg
+			if err := json.Unmarshal(data, &tree); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			doc.kind = kindTree
+			doc.project = &config.BTProjectCfg{Version: tree.Version, Select: tree.ID, Trees: []config.BTTreeCfg{tree}}
+		}
+	}
+	//文本格式的树也按@version升级
+	doc.applied, err = config.MigrateProject(doc.project)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, nil
+}
+
+//加载bundle,冲突和找不到的子树作为警告输出
+func loadBundle(path string) (*document, error) {
+	result, err := bundle.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(os.Stderr, "%s: warning: %v\n", path, c)
+	}
+	for _, r := range result.Unresolved {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", r)
+	}
+	return &document{path: path, kind: kindProject, project: result.Project, applied: result.Migrations}, nil
+}
+
+//按ID或标题查找树
+func (d *document) findTree(key string) (*config.BTTreeCfg, error) {
+	for i := range d.project.Trees {
+		if d.project.Trees[i].ID == key {
+			return &d.project.Trees[i], nil
+		}
+	}
+	for i := range d.project.Trees {
+		if d.project.Trees[i].Title == key {
+			return &d.project.Trees[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no tree %q", d.path, key)
+}
+
+//只有一棵树的文件
+func (d *document) singleTree() *config.BTTreeCfg {
+	if (d.kind == kindTree || d.kind == kindText) && len(d.project.Trees) == 1 {
+		return &d.project.Trees[0]
+	}
+	return nil
+}
+
+//编辑器里声明的自定义节点,注册成对应类别的空节点,只用于校验和打印
+func customNodeMaps(project *config.BTProjectCfg) *core.RegisterStructMaps {
+	maps := core.NewRegisterStructMaps()
+	for _, custom := range project.CustomNodes {
+		var creator core.NodeCreator
+		switch custom.Category {
+		case b3.COMPOSITE:
+			creator = func() core.IBaseNode { return new(core.Composite) }
+		case b3.DECORATOR:
+			creator = func() core.IBaseNode { return new(core.Decorator) }
+		case b3.CONDITION:
+			creator = func() core.IBaseNode { return new(core.Condition) }
+		default:
+			creator = func() core.IBaseNode { return new(core.Action) }
+		}
+		maps.Register(custom.Name, creator)
+	}
+	return maps
+}
+
+//加载工程,把BehaviorTree.Load的panic转成错误
+func buildProject(project *config.BTProjectCfg, maps *core.RegisterStructMaps) (result *core.Project, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return loader.CreateProjectFromConfig(project, maps), nil
+}
+
+//按格式输出
+func writeDocument(w io.Writer, kind, name string, project *config.BTProjectCfg, tree *config.BTTreeCfg) error {
+	var data interface{}
+	switch kind {
+	case kindText:
+		var text string
+		var err error
+		if tree != nil {
+			text, err = config.FormatTreeText(tree)
+		} else {
+			text, err = config.FormatTreesText(project.Trees)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, text)
+		return err
+	case kindTree:
+		if tree == nil {
+			return fmt.Errorf("output has %d trees, choose one with -tree", len(project.Trees))
+		}
+		data = tree
+	case kindProject:
+		data = project
+	case kindRaw:
+		data = &config.RawProjectCfg{Name: name, Data: *project}
+	default:
+		return fmt.Errorf("unknown format %q", kind)
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+//输出到文件,没有指定文件时输出到标准输出
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/magicsea/behavior3go/graph"
+)
+
+//输出DOT或Mermaid图
+func runGraph(args []string) int {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := flags.String("format", "mermaid", "output format: dot or mermaid")
+	treeKey := flags.String("tree", "", "id or title of the tree, default the selected tree")
+	inline := flags.Bool("inline", false, "render subtrees inline as clusters")
+	direction := flags.String("dir", "TB", "layout direction: TB or LR")
+	maxProps := flags.Int("props", 0, "maximum number of properties per node, 0 for all, -1 for none")
+	output := flags.String("o", "", "output file, default stdout")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *format != "dot" && *format != "mermaid" {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["graph"].usage)
+		return 2
+	}
+	doc, err := loadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 graph:", err)
+		return 2
+	}
+	key := *treeKey
+	if key == "" {
+		key = doc.project.Select
+	}
+	tree := doc.singleTree()
+	switch {
+	case key != "":
+		if tree, err = doc.findTree(key); err != nil {
+			fmt.Fprintln(os.Stderr, "b3 graph:", err)
+			return 2
+		}
+	case tree == nil && len(doc.project.Trees) > 0:
+		tree = &doc.project.Trees[0]
+	case tree == nil:
+		fmt.Fprintf(os.Stderr, "b3 graph: %s: no trees\n", doc.path)
+		return 2
+	}
+
+	opts := &graph.Options{
+		InlineSubtrees: *inline,
+		Resolve:        doc.project.FindTree,
+		MaxProperties:  *maxProps,
+		Direction:      *direction,
+	}
+	text := graph.Mermaid(tree, opts)
+	if *format == "dot" {
+		text = graph.DOT(tree, opts)
+	}
+	w, err := createOutput(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 graph:", err)
+		return 1
+	}
+	_, err = io.WriteString(w, text)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 graph:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+/*
+b3 is a command line tool for behavior tree files.
+
+Usage:
+
+	b3 <command> [arguments]
+
+Files can be editor tree json, project json, raw project (.b3) or the text
+format of config.ParseTreeText.
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+var commands = make(map[string]command)
+
+func init() {
+	commands["convert"] = command{"convert [-to format] [-tree id] [-o file] file\tconvert between tree, project, raw and text", runConvert}
+	commands["diff"] = command{"diff [-tree id] old new\tshow structural changes between two versions", runDiff}
+	commands["extract"] = command{"extract [-tree id] [-to tree|text] [-o file] file\twrite a single tree of a project", runExtract}
+	commands["graph"] = command{"graph [-format dot|mermaid] [-tree id] [-inline] [-o file] file\trender a tree as a diagram", runGraph}
+	commands["keygen"] = command{"keygen name\twrite an ed25519 key pair to name.key and name.pub", runKeygen}
+	commands["pack"] = command{"pack [-key file] bundle\twrite the checksums of a bundle, signed with the key", runPack}
+	commands["print"] = command{"print [-tree id] [-text] file\tprint trees as indented text", runPrint}
+	commands["verify"] = command{"verify [-pub file] bundle\tcheck the checksums and the signature of a bundle", runVerify}
+	commands["validate"] = command{"validate [-custom] [-v] file...\tcheck files against the built-in nodes", runValidate}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: b3 <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "b3: unknown command", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//运行命令,返回退出码和输出
+func runCommand(t *testing.T, name string, args ...string) (int, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+
+	oldOut, oldErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	code := func() int {
+		defer func() { os.Stdout, os.Stderr = oldOut, oldErr }()
+		return commands[name].run(args)
+	}()
+	return code, readFile(t, stdout.Name()), readFile(t, stderr.Name())
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLoadDocument(t *testing.T) {
+	cases := []struct {
+		path  string
+		kind  string
+		trees []string
+	}{
+		{"testdata/tree.json", kindTree, []string{"patrol"}},
+		{"testdata/project.json", kindProject, []string{"main", "patrol"}},
+		{"testdata/raw.b3", kindRaw, []string{"guard"}},
+		{"testdata/tree.bt", kindText, []string{"attack"}},
+		{"testdata/bundle", kindProject, []string{"attack", "main"}},
+		{"testdata/bundle/bundle.json", kindProject, []string{"attack", "main"}},
+	}
+	for _, c := range cases {
+		doc, err := loadDocument(c.path)
+		if err != nil {
+			t.Errorf("%s: %v", c.path, err)
+			continue
+		}
+		var ids []string
+		for _, tree := range doc.project.Trees {
+			ids = append(ids, tree.ID)
+		}
+		if doc.kind != c.kind || !reflect.DeepEqual(ids, c.trees) {
+			t.Errorf("%s: kind %s, trees %v, want %s %v", c.path, doc.kind, ids, c.kind, c.trees)
+		}
+	}
+	if doc, _ := loadDocument("testdata/raw.b3"); doc.name != "guard" {
+		t.Errorf("raw name = %q", doc.name)
+	}
+	//没有版本号的树升级到最新版本
+	if doc, _ := loadDocument("testdata/tree.json"); len(doc.applied) != 3 {
+		t.Errorf("applied = %v", doc.applied)
+	}
+	for _, path := range []string{"testdata/broken.json", "testdata/broken.bt", "testdata/missing.json"} {
+		if _, err := loadDocument(path); err == nil {
+			t.Errorf("%s: no error", path)
+		}
+	}
+}
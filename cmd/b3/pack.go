@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/magicsea/behavior3go/bundle"
+)
+
+//生成bundle.sum,有私钥时签名
+func runPack(args []string) int {
+	flags := flag.NewFlagSet("pack", flag.ExitOnError)
+	keyFile := flags.String("key", "", "file with the base64 ed25519 private key")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["pack"].usage)
+		return 2
+	}
+	var key ed25519.PrivateKey
+	if *keyFile != "" {
+		data, err := ioutil.ReadFile(*keyFile)
+		if err == nil {
+			key, err = bundle.ParsePrivateKey(string(data))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "b3 pack:", err)
+			return 2
+		}
+	}
+	sums, err := bundle.Pack(flags.Arg(0), key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 pack:", err)
+		return 1
+	}
+	signed := ""
+	if sums.Signature != "" {
+		signed = ", signed"
+	}
+	fmt.Printf("%s: %d files%s\n", flags.Arg(0), len(sums.Files), signed)
+	return 0
+}
+
+//校验bundle.sum,有公钥时校验签名
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubFile := flags.String("pub", "", "file with the base64 ed25519 public key")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["verify"].usage)
+		return 2
+	}
+	var key ed25519.PublicKey
+	if *pubFile != "" {
+		data, err := ioutil.ReadFile(*pubFile)
+		if err == nil {
+			key, err = bundle.ParsePublicKey(string(data))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "b3 verify:", err)
+			return 2
+		}
+	}
+	if _, err := bundle.LoadVerified(flags.Arg(0), key); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flags.Arg(0), err)
+		return 1
+	}
+	if key == nil {
+		fmt.Printf("%s: checksums ok, signature not checked (no -pub key)\n", flags.Arg(0))
+		return 0
+	}
+	fmt.Printf("%s: ok\n", flags.Arg(0))
+	return 0
+}
+
+//生成签名用的密钥对,写到name.key和name.pub
+func runKeygen(args []string) int {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["keygen"].usage)
+		return 2
+	}
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 keygen:", err)
+		return 1
+	}
+	name := flags.Arg(0)
+	if err := ioutil.WriteFile(name+".key", []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		fmt.Fprintln(os.Stderr, "b3 keygen:", err)
+		return 1
+	}
+	if err := ioutil.WriteFile(name+".pub", []byte(base64.StdEncoding.EncodeToString(pub)+"\n"), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "b3 keygen:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+//打印树的结构
+func runPrint(args []string) int {
+	flags := flag.NewFlagSet("print", flag.ExitOnError)
+	treeKey := flags.String("tree", "", "only print the tree with this id or title")
+	text := flags.Bool("text", false, "print in the text format without building the tree")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["print"].usage)
+		return 2
+	}
+	doc, err := loadDocument(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 print:", err)
+		return 2
+	}
+	trees := doc.project.Trees
+	if *treeKey != "" {
+		tree, err := doc.findTree(*treeKey)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "b3 print:", err)
+			return 2
+		}
+		trees = trees[:0:0]
+		trees = append(trees, *tree)
+	}
+
+	if *text {
+		if err := writeDocument(os.Stdout, kindText, "", &config.BTProjectCfg{Trees: trees}, nil); err != nil {
+			fmt.Fprintln(os.Stderr, "b3 print:", err)
+			return 1
+		}
+		return 0
+	}
+	project, err := buildProject(doc.project, customNodeMaps(doc.project))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "b3 print:", err)
+		return 1
+	}
+	for i, tree := range trees {
+		if i > 0 {
+			fmt.Println()
+		}
+		//没有ID的树用标题查找
+		bt := project.GetTreeByID(tree.ID)
+		if bt == nil {
+			bt = project.GetTreeByTitle(tree.Title)
+		}
+		if bt == nil {
+			fmt.Fprintf(os.Stderr, "b3 print: tree %q was not loaded\n", tree.Title)
+			return 1
+		}
+		fmt.Println(tree.Title)
+		bt.Print()
+	}
+	return 0
+}
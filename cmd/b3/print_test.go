@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"testdata/tree.json"}, 0, `Patrol
+Sequence
+  Wait 10ms (Wait)
+  Log info=patrol
+`},
+		{[]string{"testdata/project.json"}, 0, `Main
+Priority
+  Patrol
+    Wait milliseconds=10
+  Log info=idle
+
+Patrol
+Wait milliseconds=10
+`},
+		{[]string{"-tree", "Patrol", "testdata/project.json"}, 0, "Patrol\nWait milliseconds=10\n"},
+		{[]string{"-text", "testdata/raw.b3"}, 0, `tree "Guard" @id=guard
+  Inverter @id=i
+    Failer @id=f
+`},
+		{[]string{"testdata/unknown.json"}, 1, ""},
+		{[]string{"testdata/broken.json"}, 2, ""},
+		{[]string{"testdata/broken.bt"}, 2, ""},
+		{[]string{"-tree", "none", "testdata/project.json"}, 2, ""},
+		{nil, 2, ""},
+	}
+	for _, c := range cases {
+		code, stdout, stderr := runCommand(t, "print", c.args...)
+		if code != c.code || stdout != c.stdout {
+			t.Errorf("print %v: exit %d, output\n%s\nwant exit %d, output\n%s", c.args, code, stdout, c.code, c.stdout)
+		}
+		if code != 0 && stderr == "" {
+			t.Errorf("print %v: no error message", c.args)
+		}
+	}
+	_, _, stderr := runCommand(t, "print", "testdata/broken.bt")
+	if want := "b3 print: testdata/broken.bt: line 3: info: unterminated string\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	_, _, stderr = runCommand(t, "print", "testdata/unknown.json")
+	if !strings.Contains(stderr, "invalid node name,name:Fly") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
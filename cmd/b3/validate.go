@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+//校验文件,有错误时返回1
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	custom := flags.Bool("custom", false, "accept the custom nodes declared in the project")
+	verbose := flags.Bool("v", false, "print the applied migrations")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: b3 "+commands["validate"].usage)
+		return 2
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		doc, err := loadDocument(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		if *verbose {
+			for _, m := range doc.applied {
+				fmt.Printf("%s: migrated %s\n", path, m)
+			}
+		}
+		maps := core.NewRegisterStructMaps()
+		if *custom {
+			maps = customNodeMaps(doc.project)
+		}
+		errs := loader.ValidateProject(doc.project, maps)
+		if len(errs) == 0 {
+			if _, err := buildProject(doc.project, maps); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for _, err := range errs {
+			fmt.Printf("%s: %v\n", path, err)
+		}
+		if len(errs) > 0 {
+			status = 1
+		} else {
+			fmt.Printf("%s: ok, %d trees\n", path, len(doc.project.Trees))
+		}
+	}
+	return status
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"testdata/tree.json"}, 0, "testdata/tree.json: ok, 1 trees\n"},
+		{[]string{"testdata/project.json", "testdata/raw.b3", "testdata/tree.bt", "testdata/bundle"}, 0,
+			"testdata/project.json: ok, 2 trees\ntestdata/raw.b3: ok, 1 trees\ntestdata/tree.bt: ok, 1 trees\ntestdata/bundle: ok, 2 trees\n"},
+		{[]string{"-v", "testdata/tree.json"}, 0,
+			"testdata/tree.json: migrated parameters-to-properties 0.1.0->0.2.0 (tree Patrol)\n" +
+				"testdata/tree.json: migrated node-categories 0.2.0->0.3.0 (tree Patrol)\n" +
+				"testdata/tree.json: migrated parallel-policies 0.3.0->0.4.0 (tree Patrol)\n" +
+				"testdata/tree.json: ok, 1 trees\n"},
+		{[]string{"testdata/unknown.json"}, 1, "testdata/unknown.json: tree Unknown: node Fly(f): unknown node name\n"},
+		//后面的文件继续校验
+		{[]string{"testdata/broken.json", "testdata/tree.bt"}, 1, "testdata/tree.bt: ok, 1 trees\n"},
+		{nil, 2, ""},
+	}
+	for _, c := range cases {
+		code, stdout, _ := runCommand(t, "validate", c.args...)
+		if code != c.code || stdout != c.stdout {
+			t.Errorf("validate %v: exit %d, output\n%s\nwant exit %d, output\n%s", c.args, code, stdout, c.code, c.stdout)
+		}
+	}
+	_, _, stderr := runCommand(t, "validate", "testdata/broken.json")
+	if !strings.HasPrefix(stderr, "testdata/broken.json: ") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
@@ -0,0 +1,285 @@
+package composites
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * Conditional aborts let Priority and MemPriority react to the blackboard
+ * while a branch is running. A condition or decorator in one of their
+ * branches is marked with the `abort` property:
+ *
+ * - **self** when an observed key changes while its own branch is running
+ *   and a condition fails now, the branch is aborted.
+ * - **lowerPriority** when an observed key changes while a lower priority
+ *   branch is running and a condition succeeds now, the running branch is
+ *   aborted.
+ * - **both** both of them.
+ *
+ *     Priority
+ *       Sequence
+ *         IsShot {"abort": "lowerPriority", "observe": ["shot"]}
+ *         TakeCover
+ *       MemSequence
+ *         Patrol ...
+ *
+ * The observed keys are the blackboard references of the node properties,
+ * see `core.Value`, and the keys listed in `observe`, such as `"shot"` or
+ * `{"ref": "shot", "scope": "tree"}`. The values are compared between
+ * checks, so change a map or a slice by setting a new one. Decorators are
+ * not evaluated again: any change of an observed key aborts.
+ *
+ * The aborted branch is closed with `core.CloseNode`, then the selector
+ * evaluates its children again from the branch of the node that aborted.
+ * Nodes under a nested Priority, MemPriority or SubTree belong to that
+ * node.
+ *
+ * @class Abort
+**/
+
+type AbortMode int
+
+const (
+	AbortNone          AbortMode = 0
+	AbortSelf          AbortMode = 1
+	AbortLowerPriority AbortMode = 2
+	AbortBoth                    = AbortSelf | AbortLowerPriority
+)
+
+//解析abort属性
+func ParseAbortMode(mode string) (AbortMode, error) {
+	switch mode {
+	case "", "none":
+		return AbortNone, nil
+	case "self":
+		return AbortSelf, nil
+	case "lowerPriority":
+		return AbortLowerPriority, nil
+	case "both":
+		return AbortBoth, nil
+	}
+	return AbortNone, fmt.Errorf("unknown abort mode %q", mode)
+}
+
+func (m AbortMode) String() string {
+	switch m {
+	case AbortNone:
+		return "none"
+	case AbortSelf:
+		return "self"
+	case AbortLowerPriority:
+		return "lowerPriority"
+	case AbortBoth:
+		return "both"
+	}
+	return fmt.Sprintf("AbortMode(%d)", int(m))
+}
+
+//带abort属性的条件或装饰节点
+type aborter struct {
+	node    core.IBaseNode
+	mode    AbortMode
+	observe []core.Value
+}
+
+//Priority和MemPriority各分支里的中断节点,第一次tick时收集
+type observerAborts struct {
+	once     sync.Once
+	aborters [][]aborter
+	count    int
+}
+
+func (a *observerAborts) init(c *core.Composite) {
+	a.once.Do(func() {
+		a.aborters = make([][]aborter, c.GetChildCount())
+		for i := range a.aborters {
+			collectAborters(c.GetChild(i), &a.aborters[i])
+			a.count += len(a.aborters[i])
+		}
+	})
+}
+
+//是否有中断节点,没有时不需要记录运行的分支
+func (a *observerAborts) has(c *core.Composite) bool {
+	a.init(c)
+	return a.count > 0
+}
+
+/**
+ * Checks the observed keys of the branches. When a branch aborts, the
+ * running branch is closed and the index of the first branch to evaluate
+ * again is returned, -1 otherwise. With no running branch, -1, the values
+ * are only recorded.
+ *
+ * @method check
+ * @param {b3.Tick} tick A tick instance.
+ * @param {Composite} c The selector.
+ * @param {Integer} running The running branch, -1 for none.
+ * @return {Integer} The branch to evaluate from or -1.
+**/
+func (a *observerAborts) check(tick core.Ticker, c *core.Composite, running int) int {
+	if !a.has(c) {
+		return -1
+	}
+	restart := -1
+	for j, list := range a.aborters {
+		for _, ab := range list {
+			//每次都要记录观察的值,没有运行的分支也一样
+			if !ab.changed(tick) || running < 0 {
+				continue
+			}
+			var lower bool
+			switch {
+			case j < running && ab.mode&AbortLowerPriority != 0:
+				lower = true
+			case j == running && ab.mode&AbortSelf != 0:
+			default:
+				continue
+			}
+			if ab.node.GetCategory() == b3.CONDITION {
+				status := ab.node.Execute(tick)
+				if lower != (status == b3.SUCCESS) {
+					continue
+				}
+			}
+			if restart < 0 || j < restart {
+				restart = j
+			}
+		}
+	}
+	if restart >= 0 {
+		core.CloseNode(tick, c.GetChild(running))
+	}
+	return restart
+}
+
+//观察的值和上次检查时是否不同,第一次检查时只记录,之后在原来的切片里更新
+func (ab *aborter) changed(tick core.Ticker) bool {
+	last, ok := tick.Blackboard().Get("observed", tick.GetTree().GetID(), ab.node.GetID()).([]interface{})
+	if !ok || len(last) != len(ab.observe) {
+		last = make([]interface{}, len(ab.observe))
+		for i, v := range ab.observe {
+			last[i] = v.Get(tick)
+		}
+		tick.Blackboard().Set("observed", last, tick.GetTree().GetID(), ab.node.GetID())
+		return false
+	}
+	changed := false
+	for i, v := range ab.observe {
+		if value := v.Get(tick); !sameValue(last[i], value) {
+			last[i] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+//基本类型直接比较,其他类型用DeepEqual
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Ptr:
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func collectAborters(node core.IBaseNode, out *[]aborter) {
+	switch node.(type) {
+	case *Priority, *MemPriority, *core.SubTree:
+		return
+	}
+	switch node.GetCategory() {
+	case b3.CONDITION, b3.DECORATOR:
+		if ab, ok := newAborter(node); ok {
+			*out = append(*out, ab)
+		}
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(core.IComposite)
+		for i := 0; i < comp.GetChildCount(); i++ {
+			collectAborters(comp.GetChild(i), out)
+		}
+	case b3.DECORATOR:
+		if child := node.(core.IDecorator).GetChild(); child != nil {
+			collectAborters(child, out)
+		}
+	}
+}
+
+func newAborter(node core.IBaseNode) (aborter, bool) {
+	n, ok := node.(interface {
+		GetProperties() map[string]interface{}
+	})
+	if !ok {
+		return aborter{}, false
+	}
+	props := n.GetProperties()
+	mode, _ := props["abort"].(string)
+	abortMode, err := ParseAbortMode(mode)
+	if err != nil || abortMode == AbortNone {
+		return aborter{}, false
+	}
+	ab := aborter{node: node, mode: abortMode}
+	ab.observe, _ = ObservedKeys(node, props)
+	return ab, len(ab.observe) > 0
+}
+
+/**
+ * Returns the blackboard keys a node observes for conditional aborts: the
+ * references of its `Value` properties and the keys of its `observe`
+ * property.
+ *
+ * @method ObservedKeys
+ * @param {BaseNode} node The node, with its properties bound.
+ * @param {Object} properties The node properties.
+ * @return {Array} The observed keys as references.
+**/
+func ObservedKeys(node core.IBaseNode, properties map[string]interface{}) ([]core.Value, error) {
+	var result []core.Value
+	specs, _ := core.GetPropertySpecs(node)
+	v := reflect.Indirect(reflect.ValueOf(node))
+	for _, spec := range specs {
+		if pv, ok := v.FieldByName(spec.Field).Interface().(core.Value); ok && pv.IsRef() {
+			result = append(result, pv)
+		}
+	}
+
+	var observe []interface{}
+	switch o := properties["observe"].(type) {
+	case nil:
+	case []interface{}:
+		observe = o
+	default:
+		observe = []interface{}{o}
+	}
+	for _, raw := range observe {
+		if s, ok := raw.(string); ok && !strings.HasPrefix(s, "$") {
+			raw = "$" + s
+		}
+		pv, err := core.ParseValue(raw)
+		if err != nil {
+			return result, fmt.Errorf("observe: %v", err)
+		}
+		if !pv.IsRef() {
+			return result, fmt.Errorf("observe: %v is not a blackboard key", raw)
+		}
+		result = append(result, pv)
+	}
+	return result, nil
+}
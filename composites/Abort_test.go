@@ -0,0 +1,130 @@
+package composites_test
+
+import (
+	"testing"
+	"time"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+//第一个分支是带中断条件的MemSequence,第二个分支一直运行
+func abortTree(t *testing.T, selector, abort string) *testTree {
+	t.Helper()
+	props := ""
+	if selector == "MemPriority" {
+		props = `{"nonBlocking":true}`
+	}
+	return newTestTree(t, treeJSON("p",
+		node("p", selector, "composite", props, "s", "idle"),
+		node("s", "MemSequence", "composite", `{"nonBlocking":true}`, "x", "work"),
+		node("x", "Check", "condition", `{"key":"$x","abort":"`+abort+`"}`),
+		node("work", "Act", "action", `{"n":0}`),
+		node("idle", "Act", "action", `{"n":0}`),
+	))
+}
+
+//设置$x后tick一次,返回记录的事件
+func (tt *testTree) tickWith(t *testing.T, x bool) string {
+	t.Helper()
+	tt.board.SetMem("x", x)
+	if status := tt.run(); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	return tt.rec.take()
+}
+
+func TestAbortLowerPriority(t *testing.T) {
+	for _, selector := range []string{"Priority", "MemPriority"} {
+		tt := abortTree(t, selector, "lowerPriority")
+		if events := tt.tickWith(t, false); events != "check:x open:idle tick:idle" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+		//idle在work打开前关闭
+		if events := tt.tickWith(t, true); events != "check:x close:idle check:x open:work tick:work" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+	}
+}
+
+func TestAbortSelf(t *testing.T) {
+	for _, selector := range []string{"Priority", "MemPriority"} {
+		tt := abortTree(t, selector, "self")
+		if events := tt.tickWith(t, true); events != "check:x open:work tick:work" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+		//MemSequence不再执行条件,只有中断会检查它
+		if events := tt.tickWith(t, true); events != "tick:work" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+		if events := tt.tickWith(t, false); events != "check:x close:work check:x open:idle tick:idle" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+		//self不会中断更低优先级的分支
+		if events := tt.tickWith(t, true); selector == "MemPriority" && events != "tick:idle" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+	}
+}
+
+func TestAbortBoth(t *testing.T) {
+	for _, selector := range []string{"Priority", "MemPriority"} {
+		tt := abortTree(t, selector, "both")
+		tt.tickWith(t, false)
+		if events := tt.tickWith(t, true); events != "check:x close:idle check:x open:work tick:work" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+		if events := tt.tickWith(t, false); events != "check:x close:work check:x open:idle tick:idle" {
+			t.Errorf("%s: events = %q", selector, events)
+		}
+	}
+}
+
+func TestMemPriorityBlockingAbort(t *testing.T) {
+	//默认在一次tick里等待运行的分支,中断立即生效
+	tt := newTestTree(t, treeJSON("p",
+		node("p", "MemPriority", "composite", "", "s", "idle"),
+		node("s", "MemSequence", "composite", `{"nonBlocking":true}`, "x", "work"),
+		node("x", "Check", "condition", `{"key":"$x","abort":"lowerPriority"}`),
+		node("work", "Act", "action", `{"n":-1,"result":1}`),
+		node("idle", "Act", "action", `{"n":0}`),
+	))
+	tt.board.SetMem("x", false)
+	done := make(chan b3.Status)
+	go func() { done <- tt.run() }()
+	time.Sleep(10 * time.Millisecond)
+	tt.board.SetMem("x", true)
+	select {
+	case status := <-done:
+		if status != b3.SUCCESS {
+			t.Errorf("status = %v", status)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("the abort did not interrupt the blocking MemPriority")
+	}
+	if n := tt.rec.count("close:idle"); n != 1 {
+		t.Errorf("idle closed %d times", n)
+	}
+}
+
+func TestPriorityClosedByParentForgetsRunningChild(t *testing.T) {
+	tt := newTestTree(t, treeJSON("root",
+		node("root", "Priority", "composite", "", "stop", "p"),
+		node("stop", "Check", "condition", `{"key":"$stop"}`),
+		node("p", "Priority", "composite", "", "s", "idle"),
+		node("s", "MemSequence", "composite", `{"nonBlocking":true}`, "x", "work"),
+		node("x", "Check", "condition", `{"key":"$x","abort":"lowerPriority"}`),
+		node("work", "Act", "action", `{"n":0}`),
+		node("idle", "Act", "action", `{"n":0}`),
+	))
+	tt.tickWith(t, false)
+	if running := tt.board.GetInt("runningChild", tt.GetID(), "p"); running != 1 {
+		t.Fatalf("runningChild = %d", running)
+	}
+	tt.board.SetMem("stop", true)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if running := tt.board.GetInt("runningChild", tt.GetID(), "p"); running != -1 {
+		t.Errorf("runningChild = %d after p was closed", running)
+	}
+}
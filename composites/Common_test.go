@@ -0,0 +1,141 @@
+package composites_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+//记录节点回调的顺序
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(format string, args ...interface{}) {
+	r.mu.Lock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+	r.mu.Unlock()
+}
+
+func (r *recorder) take() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := strings.Join(r.events, " ")
+	r.events = nil
+	return s
+}
+
+func (r *recorder) count(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.events {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+//返回n次RUNNING后返回result,n为0时一直RUNNING,小于0时直接返回result,key不为空时返回黑板里的状态
+type testAction struct {
+	core.Action
+	N      int    `b3:"n,default=0"`
+	Result int    `b3:"result,default=1"`
+	Key    string `b3:"key"`
+
+	rec *recorder
+}
+
+func (a *testAction) OnOpen(tick core.Ticker) {
+	a.rec.add("open:%s", a.GetTitle())
+	tick.Blackboard().Set("i", 0, tick.GetTree().GetID(), a.GetID())
+}
+
+func (a *testAction) OnClose(tick core.Ticker) {
+	a.rec.add("close:%s", a.GetTitle())
+}
+
+func (a *testAction) OnTick(tick core.Ticker) b3.Status {
+	a.rec.add("tick:%s", a.GetTitle())
+	if a.Key != "" {
+		return b3.Status(tick.Blackboard().GetInt(a.Key, "", ""))
+	}
+	i := tick.Blackboard().GetInt("i", tick.GetTree().GetID(), a.GetID()) + 1
+	tick.Blackboard().Set("i", i, tick.GetTree().GetID(), a.GetID())
+	if a.N != 0 && i > a.N {
+		return b3.Status(a.Result)
+	}
+	return b3.RUNNING
+}
+
+//黑板键为true时成功
+type testCheck struct {
+	core.Condition
+	Key core.Value `b3:"key"`
+
+	rec *recorder
+}
+
+func (c *testCheck) OnTick(tick core.Ticker) b3.Status {
+	c.rec.add("check:%s", c.GetTitle())
+	if c.Key.Bool(tick) {
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
+
+type testTree struct {
+	*core.BehaviorTree
+	rec   *recorder
+	board *core.Blackboard
+	tick  *core.Tick
+}
+
+//从JSON创建树,节点可以用 Act 和 Check
+func newTestTree(t *testing.T, js string) *testTree {
+	t.Helper()
+	var cfg config.BTTreeCfg
+	if err := json.Unmarshal([]byte(js), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Act", func() core.IBaseNode { return &testAction{rec: rec} })
+	maps.Register("Check", func() core.IBaseNode { return &testCheck{rec: rec} })
+	if errs := loader.ValidateTree(&cfg, maps, nil); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	tree := loader.CreateBevTreeFromConfig(&cfg, maps)
+	return &testTree{BehaviorTree: tree, rec: rec, board: core.NewBlackboard(), tick: core.NewTick()}
+}
+
+func (tt *testTree) run() b3.Status {
+	return tt.Tick(tt.tick, tt.board)
+}
+
+//节点的JSON,props可以为空
+func node(id, name, category, props string, children ...string) string {
+	if props == "" {
+		props = "{}"
+	}
+	childJSON := ""
+	if len(children) > 0 {
+		childJSON = `,"children":["` + strings.Join(children, `","`) + `"]`
+	}
+	return fmt.Sprintf(`"%s":{"id":"%s","name":"%s","title":"%s","category":"%s","properties":%s%s}`,
+		id, id, name, id, category, props, childJSON)
+}
+
+//最新版本的树,加载时不再迁移
+func treeJSON(root string, nodes ...string) string {
+	return `{"version":"` + config.LatestVersion + `","id":"t","title":"t","root":"` + root + `","nodes":{` + strings.Join(nodes, ",") + `}}`
+}
@@ -0,0 +1,159 @@
+package composites
+
+import (
+	"fmt"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * ConfigurableSequence is a sequence with memory whose failure handling is
+ * set by properties. Like the original behavior3 MemSequence it returns
+ * RUNNING to the tree and resumes from the running child on the next tick.
+ *
+ * - `continueOnFailure` runs the remaining children after a failure, the
+ *   result is then given by `aggregate`: `all` (default) succeeds when all
+ *   the children succeeded, `any` when at least one did. ERROR counts as a
+ *   failure.
+ * - `restartFrom` is where the next run starts after a failed run:
+ *   `beginning` (default) or `failed`, the first child that failed.
+ * - `resetOnAbort`, true by default, forgets the running child when the
+ *   sequence is closed while RUNNING, e.g. by a higher priority branch.
+ *   When false the sequence resumes from that child when it opens again.
+ *
+ * The position is kept in the node memory as `runningChild`, the first
+ * failed child of the last run as `failedChild`.
+ *
+ * @module b3
+ * @class ConfigurableSequence
+ * @extends Composite
+**/
+type ConfigurableSequence struct {
+	core.Composite
+
+	/**
+	 * Run the remaining children after a failure.
+	 * @property {Boolean} continueOnFailure
+	**/
+	ContinueOnFailure bool `b3:"continueOnFailure,default=false"`
+
+	/**
+	 * With continueOnFailure: all or any.
+	 * @property {String} aggregate
+	**/
+	Aggregate string `b3:"aggregate,default=all"`
+
+	/**
+	 * Where a run starts after a failed run: beginning or failed.
+	 * @property {String} restartFrom
+	**/
+	RestartFrom string `b3:"restartFrom,default=beginning"`
+
+	/**
+	 * Forget the running child when closed while RUNNING.
+	 * @property {Boolean} resetOnAbort
+	**/
+	ResetOnAbort bool `b3:"resetOnAbort,default=true"`
+}
+
+/**
+ * Initialization method.
+ * @method Initialize
+ * @construCtor
+**/
+func (s *ConfigurableSequence) Initialize(setting *config.BTNodeCfg) {
+	s.Composite.Initialize(setting)
+	if s.Aggregate != "all" && s.Aggregate != "any" {
+		panic(fmt.Sprintf("ConfigurableSequence: unknown aggregate %q", s.Aggregate))
+	}
+	if s.RestartFrom != "beginning" && s.RestartFrom != "failed" {
+		panic(fmt.Sprintf("ConfigurableSequence: unknown restartFrom %q", s.RestartFrom))
+	}
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *ConfigurableSequence) OnOpen(tick core.Ticker) {
+	//上次被中断且没有重置时从运行的子节点继续
+	if running, ok := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), s.GetID()).(int); ok && running >= 0 {
+		return
+	}
+	start := 0
+	if failed, ok := tick.Blackboard().Get("failedChild", tick.GetTree().GetID(), s.GetID()).(int); ok && failed >= 0 && s.RestartFrom == "failed" {
+		start = failed
+	}
+	s.startRun(tick, start)
+}
+
+func (s *ConfigurableSequence) startRun(tick core.Ticker, start int) {
+	tick.Blackboard().Set("runningChild", start, tick.GetTree().GetID(), s.GetID())
+	tick.Blackboard().Set("failedChild", -1, tick.GetTree().GetID(), s.GetID())
+	tick.Blackboard().Set("failures", 0, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *ConfigurableSequence) OnTick(tick core.Ticker) b3.Status {
+	bb := tick.Blackboard()
+	start := bb.GetInt("runningChild", tick.GetTree().GetID(), s.GetID())
+	failures := bb.GetInt("failures", tick.GetTree().GetID(), s.GetID())
+	for i := start; i < s.GetChildCount(); i++ {
+		status := s.GetChild(i).Execute(tick)
+		switch status {
+		case b3.SUCCESS:
+			continue
+		case b3.RUNNING:
+			bb.Set("runningChild", i, tick.GetTree().GetID(), s.GetID())
+			bb.Set("failures", failures, tick.GetTree().GetID(), s.GetID())
+			return status
+		}
+		if failures == 0 {
+			bb.Set("failedChild", i, tick.GetTree().GetID(), s.GetID())
+		}
+		failures++
+		if !s.ContinueOnFailure {
+			s.finish(tick, failures)
+			return status
+		}
+	}
+	s.finish(tick, failures)
+	switch {
+	case failures == 0:
+		return b3.SUCCESS
+	case s.Aggregate == "any" && failures < s.GetChildCount():
+		return b3.SUCCESS
+	}
+	return b3.FAILURE
+}
+
+//结束一次运行,失败的子节点留给下一次运行
+func (s *ConfigurableSequence) finish(tick core.Ticker, failures int) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+	tick.Blackboard().Set("failures", failures, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Close method.
+ * @method close
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *ConfigurableSequence) OnClose(tick core.Ticker) {
+	//RUNNING时被关闭是中断
+	running, _ := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), s.GetID()).(int)
+	if running >= 0 && s.ResetOnAbort {
+		tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+	}
+}
+
+func (s *ConfigurableSequence) GetClass() string {
+	return b3.CONFIGURABLESEQUENCE
+}
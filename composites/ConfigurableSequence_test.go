@@ -0,0 +1,106 @@
+package composites_test
+
+import (
+	"reflect"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+//三个子节点的ConfigurableSequence,放在Priority下面,$stop为true时被关闭
+func configurableTree(t *testing.T, props, a, b, c string) *testTree {
+	t.Helper()
+	return newTestTree(t, treeJSON("root",
+		node("root", "Priority", "composite", "", "stop", "s"),
+		node("stop", "Check", "condition", `{"key":"$stop"}`),
+		node("s", "ConfigurableSequence", "composite", props, "a", "b", "c"),
+		node("a", "Act", "action", a),
+		node("b", "Act", "action", b),
+		node("c", "Act", "action", c),
+	))
+}
+
+func TestConfigurableSequenceResumes(t *testing.T) {
+	tt := configurableTree(t, "", `{"n":-1}`, `{"n":1}`, `{"n":-1}`)
+	if status := tt.run(); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:stop open:a tick:a close:a open:b tick:b check:stop tick:b close:b open:c tick:c close:c" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestConfigurableSequenceContinueOnFailure(t *testing.T) {
+	fail := `{"n":-1,"result":2}`
+	ok := `{"n":-1}`
+	cases := []struct {
+		props  string
+		want   b3.Status
+		ticked int
+	}{
+		{`{"continueOnFailure":true}`, b3.FAILURE, 3},
+		{`{"continueOnFailure":true,"aggregate":"any"}`, b3.SUCCESS, 3},
+		//没有continueOnFailure时aggregate不起作用
+		{`{"aggregate":"any"}`, b3.FAILURE, 1},
+	}
+	for _, c := range cases {
+		tt := configurableTree(t, c.props, fail, ok, fail)
+		if status := tt.run(); status != c.want {
+			t.Errorf("%s: status = %v, want %v", c.props, status, c.want)
+		}
+		if order := tickOrder(tt.rec.take()); len(order) != c.ticked {
+			t.Errorf("%s: ticked %v", c.props, order)
+		}
+	}
+	tt := configurableTree(t, `{"continueOnFailure":true,"aggregate":"any"}`, fail, fail, `{"n":-1,"result":4}`)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Errorf("all failed: status = %v", status)
+	}
+}
+
+func TestConfigurableSequenceRestartFrom(t *testing.T) {
+	for _, restart := range []string{"beginning", "failed"} {
+		tt := configurableTree(t, `{"restartFrom":"`+restart+`"}`, `{"n":-1}`, `{"key":"b"}`, `{"n":-1}`)
+		tt.board.SetMem("b", int(b3.FAILURE))
+		if status := tt.run(); status != b3.FAILURE {
+			t.Fatalf("%s: status = %v", restart, status)
+		}
+		tt.rec.take()
+		tt.board.SetMem("b", int(b3.SUCCESS))
+		if status := tt.run(); status != b3.SUCCESS {
+			t.Fatalf("%s: status = %v", restart, status)
+		}
+		want := []string{"a", "b", "c"}
+		if restart == "failed" {
+			want = want[1:]
+		}
+		if order := tickOrder(tt.rec.take()); !reflect.DeepEqual(order, want) {
+			t.Errorf("%s: ticked %v, want %v", restart, order, want)
+		}
+	}
+}
+
+func TestConfigurableSequenceResetOnAbort(t *testing.T) {
+	for _, reset := range []bool{true, false} {
+		props := `{"resetOnAbort":false}`
+		if reset {
+			props = ""
+		}
+		tt := configurableTree(t, props, `{"n":-1}`, `{"n":0}`, `{"n":-1}`)
+		tt.run()
+		tt.board.SetMem("stop", true)
+		if status := tt.run(); status != b3.SUCCESS {
+			t.Fatalf("status = %v", status)
+		}
+		tt.board.SetMem("stop", false)
+		tt.rec.take()
+		tt.run()
+		first := tickOrder(tt.rec.take())[0]
+		if want := map[bool]string{true: "a", false: "b"}[reset]; first != want {
+			t.Errorf("resetOnAbort %v: resumed from %s, want %s", reset, first, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * IfThenElse has two or three children: a condition, the then branch and
+ * the optional else branch. It ticks the condition, then the then branch on
+ * SUCCESS or the else branch on FAILURE, and returns the status of the
+ * branch. Without else branch a failed condition returns FAILURE.
+ *
+ * While the chosen branch is RUNNING it is resumed on the next ticks
+ * without checking the condition again, the branch is kept in the node
+ * memory as `runningChild`. A RUNNING condition returns RUNNING and is
+ * ticked again.
+ *
+ * @module b3
+ * @class IfThenElse
+ * @extends Composite
+**/
+type IfThenElse struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *IfThenElse) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *IfThenElse) OnTick(tick core.Ticker) b3.Status {
+	childNum := s.GetChildCount()
+	if childNum != 2 && childNum != 3 {
+		return b3.ERROR
+	}
+	branch, _ := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), s.GetID()).(int)
+	if branch <= 0 {
+		switch status := s.GetChild(0).Execute(tick); status {
+		case b3.SUCCESS:
+			branch = 1
+		case b3.FAILURE:
+			if childNum < 3 {
+				return b3.FAILURE
+			}
+			branch = 2
+		default:
+			return status
+		}
+	}
+	status := s.GetChild(branch).Execute(tick)
+	if status != b3.RUNNING {
+		branch = -1
+	}
+	tick.Blackboard().Set("runningChild", branch, tick.GetTree().GetID(), s.GetID())
+	return status
+}
+
+func (s *IfThenElse) GetClass() string {
+	return b3.IFTHENELSE
+}
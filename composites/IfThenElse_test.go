@@ -0,0 +1,63 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+//条件读取$ok,then和else的参数可以为空,else为空时只有两个子节点
+func branchTree(t *testing.T, name, then, otherwise string) *testTree {
+	t.Helper()
+	children := []string{"ok", "then"}
+	nodes := []string{
+		node("ok", "Check", "condition", `{"key":"$ok"}`),
+		node("then", "Act", "action", then),
+	}
+	if otherwise != "" {
+		children = append(children, "else")
+		nodes = append(nodes, node("else", "Act", "action", otherwise))
+	}
+	nodes = append(nodes, node("b", name, "composite", "", children...))
+	return newTestTree(t, treeJSON("b", nodes...))
+}
+
+func TestIfThenElseBranches(t *testing.T) {
+	tt := branchTree(t, "IfThenElse", `{"n":-1}`, `{"n":-1,"result":4}`)
+	tt.board.SetMem("ok", true)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Errorf("then: status = %v", status)
+	}
+	tt.board.SetMem("ok", false)
+	if status := tt.run(); status != b3.ERROR {
+		t.Errorf("else: status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:ok open:then tick:then close:then check:ok open:else tick:else close:else" {
+		t.Errorf("events = %q", events)
+	}
+
+	tt = branchTree(t, "IfThenElse", `{"n":-1}`, "")
+	if status := tt.run(); status != b3.FAILURE {
+		t.Errorf("no else: status = %v", status)
+	}
+}
+
+func TestIfThenElseResumesBranch(t *testing.T) {
+	tt := branchTree(t, "IfThenElse", `{"n":2}`, `{"n":-1}`)
+	tt.board.SetMem("ok", true)
+	tt.run()
+	//运行中的分支继续执行,不再检查条件
+	tt.board.SetMem("ok", false)
+	tt.run()
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:ok open:then tick:then tick:then tick:then close:then" {
+		t.Errorf("events = %q", events)
+	}
+	//结束后重新检查条件
+	tt.run()
+	if events := tt.rec.take(); events != "check:ok open:else tick:else close:else" {
+		t.Errorf("events = %q", events)
+	}
+}
@@ -1,13 +1,40 @@
 package composites
 
 import (
+	"context"
+
 	b3 "github.com/magicsea/behavior3go"
 	"github.com/magicsea/behavior3go/core"
-	"time"
 )
 
+/**
+ * MemPriority ticks its children in order until one does not return
+ * FAILURE, and resumes from the running child, kept in the node memory as
+ * `runningChild`.
+ *
+ * By default it keeps ticking a RUNNING child inside the same tick until it
+ * finishes. With `nonBlocking` it returns RUNNING to the tree instead and
+ * resumes on the next tick. Conditional aborts, see `Abort`, are checked
+ * at the start of every tick, and before every tick of a blocking child,
+ * so they take effect at once. Like MemSequence, a blocking MemPriority
+ * stops waiting when the `cancelCtx` of a Parallel or Race ancestor is
+ * cancelled.
+ *
+ * @module b3
+ * @class MemPriority
+ * @extends Composite
+**/
 type MemPriority struct {
 	core.Composite
+
+	/**
+	 * Return RUNNING to the tree instead of ticking the child again.
+	 * @property {Boolean} nonBlocking
+	**/
+	NonBlocking bool `b3:"nonBlocking,default=false"`
+
+	//条件中断,见Abort
+	aborts observerAborts
 }
 
 /**
@@ -27,11 +54,33 @@ func (p *MemPriority) OnOpen(tick core.Ticker) {
 **/
 func (p *MemPriority) OnTick(tick core.Ticker) b3.Status {
 	var child = tick.Blackboard().GetInt("runningChild", tick.GetTree().GetID(), p.GetID())
+	//上次tick返回RUNNING的分支还打开着
+	running := -1
+	if child < p.GetChildCount() && p.GetChild(child).IsOpen(tick) {
+		running = child
+	}
+	if restart := p.aborts.check(tick, &p.Composite, running); restart >= 0 {
+		child = restart
+	}
+	cancelCtx := context.Background()
+	if ctx := core.AncestorValue(tick, p, "cancelCtx"); ctx != nil {
+		cancelCtx = ctx.(context.Context)
+	}
 	for i := child; i < p.GetChildCount(); i++ {
+		tick.Blackboard().Set("runningChild", i, tick.GetTree().GetID(), p.GetID())
 		var status = p.GetChild(i).Execute(tick)
-		for status == b3.RUNNING {
-			tick.Blackboard().Set("runningChild", i, tick.GetTree().GetID(), p.GetID())
-			time.Sleep(time.Second)
+		for status == b3.RUNNING && !p.NonBlocking {
+			select {
+			case <-cancelCtx.Done():
+				status = b3.SUCCESS
+				continue
+			default:
+			}
+			//中断时关闭运行的分支,从中断的分支重新评估
+			if restart := p.aborts.check(tick, &p.Composite, i); restart >= 0 {
+				i = restart
+				tick.Blackboard().Set("runningChild", i, tick.GetTree().GetID(), p.GetID())
+			}
 			status = p.GetChild(i).Execute(tick)
 		}
 		if status != b3.FAILURE {
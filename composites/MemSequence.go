@@ -7,8 +7,27 @@ import (
 	"github.com/magicsea/behavior3go/core"
 )
 
+/**
+ * MemSequence ticks its children in order until one does not return
+ * SUCCESS, and resumes from the running child, kept in the node memory as
+ * `runningChild`.
+ *
+ * By default it keeps ticking a RUNNING child inside the same tick until it
+ * finishes. With `nonBlocking` it returns RUNNING to the tree instead, like
+ * the original behavior3 MemSequence, and resumes on the next tick.
+ *
+ * @module b3
+ * @class MemSequence
+ * @extends Composite
+**/
 type MemSequence struct {
 	core.Composite
+
+	/**
+	 * Return RUNNING to the tree instead of ticking the child again.
+	 * @property {Boolean} nonBlocking
+	**/
+	NonBlocking bool `b3:"nonBlocking,default=false"`
 }
 
 /**
@@ -29,13 +48,13 @@ func (s *MemSequence) OnOpen(tick core.Ticker) {
 func (s *MemSequence) OnTick(tick core.Ticker) b3.Status {
 	child := tick.Blackboard().GetInt("runningChild", tick.GetTree().GetID(), s.GetID())
 	cancelCtx := context.Background()
-	if ctx := s.GetValueFromAncestor("cancelCtx", tick.Blackboard()); ctx != nil {
+	if ctx := core.AncestorValue(tick, s, "cancelCtx"); ctx != nil {
 		cancelCtx = ctx.(context.Context)
 	}
 	for i := child; i < s.GetChildCount(); i++ {
 		tick.Blackboard().Set("runningChild", i, tick.GetTree().GetID(), s.GetID())
 		var status = s.GetChild(i).Execute(tick)
-		for status == b3.RUNNING {
+		for status == b3.RUNNING && !s.NonBlocking {
 			select {
 			case <-cancelCtx.Done():
 				status = b3.SUCCESS
@@ -2,14 +2,99 @@ package composites
 
 import (
 	"context"
+	"sync"
 
 	b3 "github.com/magicsea/behavior3go"
 	"github.com/magicsea/behavior3go/core"
 )
 
+/**
+ * Parallel ticks all its children at the same time until the policies
+ * decide the result:
+ *
+ * - SUCCESS when `finishThreshold` children finished, whatever their
+ *   status, 0 to disable.
+ * - SUCCESS when `successThreshold` children succeeded, 0 for all.
+ * - FAILURE when `failureThreshold` children failed, 0 for all. ERROR
+ *   counts as a failure.
+ *
+ * With the defaults it runs until all the children succeeded or one of
+ * them failed. If all the children finished without reaching a threshold
+ * it returns FAILURE. With `cancelRemaining` the children still running when
+ * the result is decided are closed, otherwise Parallel returns RUNNING
+ * until they finish.
+ *
+ * Every tree tick, each child that is still running is ticked once, in its
+ * own goroutine, and Parallel returns RUNNING until the result is decided.
+ * With `cooperative` it starts no goroutine and ticks the children in the
+ * caller's goroutine, in order. Use it when the agents must be ticked
+ * deterministically from a single goroutine. Nothing cancels a descendant
+ * that blocks in its tick then, so the tree would never return,
+ * `loader.ValidateTree` reports such descendants.
+ *
+ * The status of every child is kept in the node memory, see
+ * `ChildStatuses`. A child that was cancelled stays RUNNING.
+ *
+ * The descendants that block in their tick, such as Sequence and
+ * Subscription, find the `cancelCtx` context of the node memory with
+ * `core.AncestorValue`. It is cancelled as soon as the result is decided
+ * with `cancelRemaining`, and when Parallel is closed. Until then such a
+ * descendant holds the tick of Parallel, use a sequence that returns
+ * RUNNING, such as ConfigurableSequence, for the branches that run for
+ * several ticks.
+ *
+ * Documents older than version 0.4.0 get `finishThreshold=1` and
+ * `cancelRemaining=true`, the behavior of the old Parallel, from the
+ * `parallel-policies` migration. Nodes created in code get the defaults
+ * of the properties from `Ctor`.
+ *
+ * @module b3
+ * @class Parallel
+ * @extends Composite
+**/
 type Parallel struct {
 	core.Composite
-	cancel context.CancelFunc
+
+	/**
+	 * Number of children that must finish, 0 to disable.
+	 * @property {Integer} finishThreshold
+	**/
+	FinishThreshold int `b3:"finishThreshold,min=0,default=0"`
+
+	/**
+	 * Number of children that must succeed, 0 for all.
+	 * @property {Integer} successThreshold
+	**/
+	SuccessThreshold int `b3:"successThreshold,min=0,default=0"`
+
+	/**
+	 * Number of children that must fail, 0 for all.
+	 * @property {Integer} failureThreshold
+	**/
+	FailureThreshold int `b3:"failureThreshold,min=0,default=1"`
+
+	/**
+	 * Close the running children when the result is decided.
+	 * @property {Boolean} cancelRemaining
+	**/
+	CancelRemaining bool `b3:"cancelRemaining,default=true"`
+
+	/**
+	 * Tick the children in the caller's goroutine.
+	 * @property {Boolean} cooperative
+	**/
+	Cooperative bool `b3:"cooperative,default=false"`
+}
+
+/**
+ * Constructor, sets the defaults of the properties that are not zero.
+ * @method Ctor
+ * @construCtor
+**/
+func (p *Parallel) Ctor() {
+	p.Composite.Ctor()
+	p.FailureThreshold = 1
+	p.CancelRemaining = true
 }
 
 /**
@@ -18,9 +103,15 @@ type Parallel struct {
  * @param {b3.Tick} tick A tick instance.
 **/
 func (p *Parallel) OnOpen(tick core.Ticker) {
+	statuses := make([]b3.Status, p.GetChildCount())
+	for i := range statuses {
+		statuses[i] = b3.RUNNING
+	}
+	tick.Blackboard().Set("childStatus", statuses, tick.GetTree().GetID(), p.GetID())
+	tick.Blackboard().Set("decided", b3.RUNNING, tick.GetTree().GetID(), p.GetID())
 	ctx, cancel := context.WithCancel(context.Background())
-	p.cancel = cancel
-	tick.Blackboard().Set("cancelCtx", ctx, p.GetTreeID(), p.GetID())
+	tick.Blackboard().Set("cancelCtx", ctx, tick.GetTree().GetID(), p.GetID())
+	tick.Blackboard().Set("cancelFunc", cancel, tick.GetTree().GetID(), p.GetID())
 }
 
 /**
@@ -31,33 +122,149 @@ func (p *Parallel) OnOpen(tick core.Ticker) {
 **/
 func (p *Parallel) OnTick(tick core.Ticker) b3.Status {
 	childNum := p.GetChildCount()
-	rs := make(chan b3.Status, childNum)
-	ctx := tick.Blackboard().Get("cancelCtx", p.GetTreeID(), p.GetID()).(context.Context)
-	for i := 0; i < childNum; i++ {
-		child := p.GetChild(i)
-		nt := tick.TearTick()
-		go func() {
+	statuses := p.ChildStatuses(tick)
+	if len(statuses) != childNum {
+		p.OnOpen(tick)
+		statuses = p.ChildStatuses(tick)
+	}
+	//已经确定的结果,RUNNING表示还没有确定,等待没有取消的子节点结束
+	decided, _ := tick.Blackboard().Get("decided", tick.GetTree().GetID(), p.GetID()).(b3.Status)
+	p.tickRunning(tick, statuses)
+	running := 0
+	for _, status := range statuses {
+		if status == b3.RUNNING {
+			running++
+		}
+	}
+
+	if decided == b3.RUNNING {
+		decided = p.decide(statuses)
+		switch {
+		case decided != b3.RUNNING:
+			tick.Blackboard().Set("decided", decided, tick.GetTree().GetID(), p.GetID())
+		case running == 0:
+			return b3.FAILURE
+		default:
+			return b3.RUNNING
+		}
+	}
+
+	if running > 0 && p.CancelRemaining {
+		p.closeRunning(tick, statuses)
+		running = 0
+	}
+	if running > 0 {
+		return b3.RUNNING
+	}
+	return decided
+}
+
+/**
+ * Close method.
+ * @method close
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *Parallel) OnClose(tick core.Ticker) {
+	p.cancelChildren(tick)
+	p.closeRunning(tick, p.ChildStatuses(tick))
+}
+
+//运行中的子节点各执行一次,非单线程模式时每个子节点一个goroutine
+func (p *Parallel) tickRunning(tick core.Ticker, statuses []b3.Status) {
+	if p.Cooperative {
+		for i, status := range statuses {
+			if status == b3.RUNNING {
+				statuses[i] = p.GetChild(i).Execute(tick)
+			}
+		}
+		return
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	cancelled := false
+	for i, status := range statuses {
+		if status != b3.RUNNING {
+			continue
+		}
+		wg.Add(1)
+		child, nt := p.GetChild(i), tick.TearTick()
+		go func(i int) {
+			defer wg.Done()
 			status := child.Execute(nt)
-			for status == b3.RUNNING {
-				select {
-				case <-ctx.Done():
-					status = b3.SUCCESS
-				default:
-					status = child.Execute(nt)
-				}
+			mu.Lock()
+			defer mu.Unlock()
+			//结果确定后被取消的子节点保持RUNNING,由closeRunning关闭
+			if cancelled {
+				return
+			}
+			statuses[i] = status
+			if p.CancelRemaining && p.decide(statuses) != b3.RUNNING {
+				cancelled = true
+				p.cancelChildren(tick)
 			}
-			rs <- status
-		}()
+		}(i)
+	}
+	wg.Wait()
+}
+
+//取消cancelCtx,结束阻塞的子孙节点
+func (p *Parallel) cancelChildren(tick core.Ticker) {
+	if cancel, ok := tick.Blackboard().Get("cancelFunc", tick.GetTree().GetID(), p.GetID()).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
+//关闭还在运行的子节点,被取消的子节点状态保持RUNNING
+func (p *Parallel) closeRunning(tick core.Ticker, statuses []b3.Status) {
+	for i, status := range statuses {
+		if status == b3.RUNNING && i < p.GetChildCount() {
+			core.CloseNode(tick, p.GetChild(i))
+		}
+	}
+}
+
+//按阈值确定的结果,还不能确定时返回RUNNING
+func (p *Parallel) decide(statuses []b3.Status) b3.Status {
+	finishNeed, successNeed, failureNeed := p.thresholds(len(statuses))
+	var successes, failures int
+	for _, status := range statuses {
+		switch status {
+		case b3.SUCCESS:
+			successes++
+		case b3.FAILURE, b3.ERROR:
+			failures++
+		}
 	}
-	var finish int
-	<-rs
-	finish++
-	p.cancel()
-	for finish < childNum {
-		<-rs
-		finish++
+	switch {
+	case len(statuses) == 0 || finishNeed > 0 && successes+failures >= finishNeed:
+		return b3.SUCCESS
+	case successes >= successNeed:
+		return b3.SUCCESS
+	case failures >= failureNeed:
+		return b3.FAILURE
 	}
-	return b3.SUCCESS
+	return b3.RUNNING
+}
+
+//结束、成功和失败需要的子节点数
+func (p *Parallel) thresholds(childNum int) (int, int, int) {
+	finish, success, failure := p.FinishThreshold, p.SuccessThreshold, p.FailureThreshold
+	if finish > childNum {
+		finish = childNum
+	}
+	if success <= 0 || success > childNum {
+		success = childNum
+	}
+	if failure <= 0 || failure > childNum {
+		failure = childNum
+	}
+	return finish, success, failure
+}
+
+//每个子节点最后的状态
+func (p *Parallel) ChildStatuses(tick core.Ticker) []b3.Status {
+	statuses, _ := tick.Blackboard().Get("childStatus", tick.GetTree().GetID(), p.GetID()).([]b3.Status)
+	return statuses
 }
 
 func (p *Parallel) GetClass() string {
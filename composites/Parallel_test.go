@@ -0,0 +1,231 @@
+package composites_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/composites"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+//两个子节点的Parallel,cooperative为true时加上单线程模式
+func parallelTree(t *testing.T, props string, cooperative bool, a, b string) *testTree {
+	t.Helper()
+	if cooperative {
+		props = strings.Replace(props, "{", `{"cooperative":true,`, 1)
+		props = strings.Replace(props, ",}", "}", 1)
+	}
+	return newTestTree(t, treeJSON("p",
+		node("p", "Parallel", "composite", props, "a", "b"),
+		node("a", "Act", "action", a),
+		node("b", "Act", "action", b),
+	))
+}
+
+//执行到结果不是RUNNING为止,最多10次
+func runUntilDone(tt *testTree) []b3.Status {
+	var result []b3.Status
+	for i := 0; i < 10; i++ {
+		status := tt.run()
+		result = append(result, status)
+		if status != b3.RUNNING {
+			break
+		}
+	}
+	return result
+}
+
+func TestParallelPolicies(t *testing.T) {
+	R, S, F := b3.RUNNING, b3.SUCCESS, b3.FAILURE
+	cases := []struct {
+		name     string
+		props    string
+		a, b     string
+		want     []b3.Status
+		children []b3.Status
+		closed   string
+	}{
+		//默认:全部成功或任意一个失败
+		{"all succeed", `{}`, `{"n":1}`, `{"n":3}`, []b3.Status{R, R, R, S}, []b3.Status{S, S}, ""},
+		{"any fails", `{}`, `{"n":1,"result":2}`, `{"n":0}`, []b3.Status{R, F}, []b3.Status{F, R}, "b"},
+		{"error fails", `{}`, `{"n":0}`, `{"n":1,"result":4}`, []b3.Status{R, F}, []b3.Status{R, b3.ERROR}, "a"},
+		{"wait for all", `{"failureThreshold":0,"cancelRemaining":false}`, `{"n":1,"result":2}`, `{"n":3}`,
+			[]b3.Status{R, R, R, F}, []b3.Status{F, S}, ""},
+		{"decided but waiting", `{"cancelRemaining":false}`, `{"n":1,"result":2}`, `{"n":2}`,
+			[]b3.Status{R, R, F}, []b3.Status{F, S}, ""},
+		{"first success", `{"successThreshold":1}`, `{"n":0}`, `{"n":1}`, []b3.Status{R, S}, []b3.Status{R, S}, "a"},
+		//原来的Parallel:第一个子节点结束就成功
+		{"first finished", `{"finishThreshold":1}`, `{"n":1,"result":2}`, `{"n":0}`, []b3.Status{R, S}, []b3.Status{F, R}, "b"},
+	}
+	for _, c := range cases {
+		for _, cooperative := range []bool{false, true} {
+			name := fmt.Sprintf("%s (cooperative %v)", c.name, cooperative)
+			tt := parallelTree(t, c.props, cooperative, c.a, c.b)
+			if got := runUntilDone(tt); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%s: statuses %v, want %v", name, got, c.want)
+				continue
+			}
+			p := tt.GetRoot().(*composites.Parallel)
+			if got := p.ChildStatuses(tt.tick); !reflect.DeepEqual(got, c.children) {
+				t.Errorf("%s: child statuses %v, want %v", name, got, c.children)
+			}
+			//结束的子节点自己关闭,被取消的子节点由Parallel关闭
+			for _, id := range []string{"a", "b"} {
+				if n := tt.rec.count("close:" + id); n != 1 {
+					t.Errorf("%s: %s closed %d times", name, id, n)
+				}
+			}
+			if c.closed != "" && p.GetChild(int(c.closed[0]-'a')).IsOpen(tt.tick) {
+				t.Errorf("%s: cancelled child %s is still open", name, c.closed)
+			}
+		}
+	}
+}
+
+func TestParallelTicksOncePerTreeTick(t *testing.T) {
+	for _, cooperative := range []bool{false, true} {
+		tt := parallelTree(t, `{}`, cooperative, `{"n":0}`, `{"n":0}`)
+		for i := 0; i < 3; i++ {
+			if status := tt.run(); status != b3.RUNNING {
+				t.Fatalf("tick %d: status = %v", i, status)
+			}
+		}
+		if a, b := tt.rec.count("tick:a"), tt.rec.count("tick:b"); a != 3 || b != 3 {
+			t.Errorf("cooperative %v: a ticked %d and b %d times, want 3", cooperative, a, b)
+		}
+	}
+}
+
+func TestParallelClosedByParent(t *testing.T) {
+	for _, cooperative := range []bool{false, true} {
+		props := `{}`
+		if cooperative {
+			props = `{"cooperative":true}`
+		}
+		tt := newTestTree(t, treeJSON("root",
+			node("root", "Priority", "composite", "", "stop", "p"),
+			node("stop", "Check", "condition", `{"key":"$stop"}`),
+			node("p", "Parallel", "composite", props, "a", "b"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("b", "Act", "action", `{"n":0}`),
+		))
+		if status := tt.run(); status != b3.RUNNING {
+			t.Fatalf("status = %v", status)
+		}
+		tt.board.SetMem("stop", true)
+		if status := tt.run(); status != b3.SUCCESS {
+			t.Fatalf("status = %v", status)
+		}
+		if a, b := tt.rec.count("close:a"), tt.rec.count("close:b"); a != 1 || b != 1 {
+			t.Errorf("cooperative %v: a closed %d and b %d times, want 1", cooperative, a, b)
+		}
+	}
+}
+
+func TestParallelCancelsBlockingChildren(t *testing.T) {
+	//阻塞的节点在一次tick里等待运行的子节点,结果确定时通过cancelCtx结束
+	for _, blocking := range []string{"Sequence", "MemSequence", "MemPriority"} {
+		tt := newTestTree(t, treeJSON("p",
+			node("p", "Parallel", "composite", "", "a", "s"),
+			node("a", "Act", "action", `{"n":-1,"result":2}`),
+			node("s", blocking, "composite", "", "b"),
+			node("b", "Act", "action", `{"n":0}`),
+		))
+		done := make(chan b3.Status)
+		go func() { done <- tt.run() }()
+		select {
+		case status := <-done:
+			if status != b3.FAILURE {
+				t.Errorf("%s: status = %v", blocking, status)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Parallel did not cancel the blocking %s", blocking)
+		}
+		if n := tt.rec.count("close:b"); n != 1 {
+			t.Errorf("%s: b closed %d times", blocking, n)
+		}
+	}
+}
+
+func TestParallelDefaults(t *testing.T) {
+	//代码创建的节点和加载的节点默认值一致
+	var code composites.Parallel
+	code.Ctor()
+	loaded := parallelTree(t, `{}`, false, `{"n":1}`, `{"n":1}`).GetRoot().(*composites.Parallel)
+	if code.FinishThreshold != loaded.FinishThreshold || code.SuccessThreshold != loaded.SuccessThreshold ||
+		code.FailureThreshold != loaded.FailureThreshold || code.CancelRemaining != loaded.CancelRemaining ||
+		code.Cooperative != loaded.Cooperative {
+		t.Errorf("Ctor defaults %+v, loaded %+v", code, *loaded)
+	}
+}
+
+func TestParallelMigratedOnLoad(t *testing.T) {
+	//没有版本号的文档按原来的Parallel执行:第一个子节点结束就成功
+	js := treeJSON("p",
+		node("p", "Parallel", "composite", "", "a", "b"),
+		node("a", "Act", "action", `{"n":1,"result":2}`),
+		node("b", "Act", "action", `{"n":0}`),
+	)
+	tt := newTestTree(t, strings.Replace(js, `"version":"`+config.LatestVersion+`",`, "", 1))
+	if got, want := runUntilDone(tt), []b3.Status{b3.RUNNING, b3.SUCCESS}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses %v, want %v", got, want)
+	}
+}
+
+func TestParallelCooperativeBlocking(t *testing.T) {
+	cases := []struct {
+		name  string
+		nodes []string
+		err   string
+	}{
+		{"sequence", []string{
+			node("p", "Parallel", "composite", `{"cooperative":true}`, "a", "i"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("i", "Priority", "composite", "", "s"),
+			node("s", "Sequence", "composite", "", "b"),
+			node("b", "Act", "action", `{"n":0}`),
+		}, "tree t: node Parallel(p): cooperative Parallel cannot cancel the blocking Sequence(s)"},
+		{"non-blocking", []string{
+			node("p", "Parallel", "composite", `{"cooperative":true}`, "a", "s"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("s", "MemSequence", "composite", `{"nonBlocking":true}`, "b"),
+			node("b", "Act", "action", `{"n":0}`),
+		}, ""},
+		//内层的Parallel自己取消阻塞的节点
+		{"nested parallel", []string{
+			node("p", "Parallel", "composite", `{"cooperative":true}`, "a", "q"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("q", "Parallel", "composite", "", "s"),
+			node("s", "Sequence", "composite", "", "b"),
+			node("b", "Act", "action", `{"n":0}`),
+		}, ""},
+		{"goroutines", []string{
+			node("p", "Parallel", "composite", "", "a", "s"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("s", "Sequence", "composite", "", "b"),
+			node("b", "Act", "action", `{"n":0}`),
+		}, ""},
+	}
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Act", func() core.IBaseNode { return &testAction{rec: &recorder{}} })
+	for _, c := range cases {
+		var cfg config.BTTreeCfg
+		if err := json.Unmarshal([]byte(treeJSON("p", c.nodes...)), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		errs := loader.ValidateTree(&cfg, maps, nil)
+		switch {
+		case c.err == "" && len(errs) > 0:
+			t.Errorf("%s: errors %v", c.name, errs)
+		case c.err != "" && (len(errs) != 1 || errs[0].Error() != c.err):
+			t.Errorf("%s: errors %v, want %s", c.name, errs, c.err)
+		}
+	}
+}
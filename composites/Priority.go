@@ -7,6 +7,9 @@ import (
 
 type Priority struct {
 	core.Composite
+
+	//条件中断,见Abort
+	aborts observerAborts
 }
 
 /**
@@ -16,15 +19,50 @@ type Priority struct {
  * @return {Constant} A state constant.
 **/
 func (p *Priority) OnTick(tick core.Ticker) b3.Status {
+	//没有条件中断时不记录运行的分支
+	if !p.aborts.has(&p.Composite) {
+		for i := 0; i < p.GetChildCount(); i++ {
+			var status = p.GetChild(i).Execute(tick)
+			if status != b3.FAILURE {
+				return status
+			}
+		}
+		return b3.FAILURE
+	}
+
+	running, ok := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), p.GetID()).(int)
+	if !ok {
+		running = -1
+	}
+	//每次tick都从第一个子节点开始,中断只需要关闭运行的分支
+	p.aborts.check(tick, &p.Composite, running)
 	for i := 0; i < p.GetChildCount(); i++ {
 		var status = p.GetChild(i).Execute(tick)
 		if status != b3.FAILURE {
+			running = -1
+			if status == b3.RUNNING {
+				running = i
+			}
+			tick.Blackboard().Set("runningChild", running, tick.GetTree().GetID(), p.GetID())
 			return status
 		}
 	}
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), p.GetID())
 	return b3.FAILURE
 }
 
+/**
+ * Close method.
+ * @method close
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *Priority) OnClose(tick core.Ticker) {
+	//被父节点关闭时忘记运行的分支,下次打开时不会中断它
+	if p.aborts.has(&p.Composite) {
+		tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), p.GetID())
+	}
+}
+
 func (p *Priority) GetClass() string {
 	return b3.PRIORITY
 }
@@ -0,0 +1,135 @@
+package composites
+
+import (
+	"context"
+	"sync"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * Race ticks all its children and returns the status of the first one that
+ * finishes, whatever it is: SUCCESS, FAILURE or ERROR. The other children
+ * are closed, calling their `close` callbacks. It is used for "do X unless
+ * a timeout or an event happens first":
+ *
+ *     Race
+ *       MoveTo
+ *       Wait 5000ms
+ *
+ * Every tree tick, each child is ticked once, in its own goroutine like
+ * Parallel, and Race returns RUNNING until a child finishes. When several
+ * finish in the same tick the first one to return wins. With `cooperative`
+ * the children are ticked in order in the caller's goroutine, and the
+ * children after the winner are not ticked.
+ *
+ * Like Parallel, Race shares a `cancelCtx` context with its blocking
+ * descendants, cancelled as soon as a child finishes and when Race is
+ * closed.
+ *
+ * @module b3
+ * @class Race
+ * @extends Composite
+**/
+type Race struct {
+	core.Composite
+
+	/**
+	 * Tick the children in the caller's goroutine.
+	 * @property {Boolean} cooperative
+	**/
+	Cooperative bool `b3:"cooperative,default=false"`
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (r *Race) OnOpen(tick core.Ticker) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tick.Blackboard().Set("cancelCtx", ctx, tick.GetTree().GetID(), r.GetID())
+	tick.Blackboard().Set("cancelFunc", cancel, tick.GetTree().GetID(), r.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (r *Race) OnTick(tick core.Ticker) b3.Status {
+	childNum := r.GetChildCount()
+	if childNum == 0 {
+		return b3.SUCCESS
+	}
+	winner, status := r.tickChildren(tick)
+	if winner < 0 {
+		return b3.RUNNING
+	}
+	r.closeOthers(tick, winner)
+	return status
+}
+
+//每个子节点执行一次,返回最先结束的子节点和它的状态,没有结束的返回-1
+func (r *Race) tickChildren(tick core.Ticker) (int, b3.Status) {
+	if r.Cooperative {
+		for i := 0; i < r.GetChildCount(); i++ {
+			if status := r.GetChild(i).Execute(tick); status != b3.RUNNING {
+				return i, status
+			}
+		}
+		return -1, b3.RUNNING
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	winner, result := -1, b3.RUNNING
+	for i := 0; i < r.GetChildCount(); i++ {
+		wg.Add(1)
+		child, nt := r.GetChild(i), tick.TearTick()
+		go func(i int) {
+			defer wg.Done()
+			status := child.Execute(nt)
+			mu.Lock()
+			defer mu.Unlock()
+			//获胜者之后结束的子节点是被取消的
+			if winner < 0 && status != b3.RUNNING {
+				winner, result = i, status
+				r.cancelChildren(tick)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return winner, result
+}
+
+//关闭获胜者以外还打开的子节点
+func (r *Race) closeOthers(tick core.Ticker, winner int) {
+	for i := 0; i < r.GetChildCount(); i++ {
+		if i != winner {
+			core.CloseNode(tick, r.GetChild(i))
+		}
+	}
+}
+
+/**
+ * Close method.
+ * @method close
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (r *Race) OnClose(tick core.Ticker) {
+	r.cancelChildren(tick)
+	r.closeOthers(tick, -1)
+}
+
+//取消cancelCtx,结束阻塞的子孙节点
+func (r *Race) cancelChildren(tick core.Ticker) {
+	if cancel, ok := tick.Blackboard().Get("cancelFunc", tick.GetTree().GetID(), r.GetID()).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
+func (r *Race) GetClass() string {
+	return b3.RACE
+}
@@ -0,0 +1,120 @@
+package composites_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+//两个子节点的Race
+func raceTree(t *testing.T, cooperative bool, a, b string) *testTree {
+	t.Helper()
+	props := ""
+	if cooperative {
+		props = `{"cooperative":true}`
+	}
+	return newTestTree(t, treeJSON("r",
+		node("r", "Race", "composite", props, "a", "b"),
+		node("a", "Act", "action", a),
+		node("b", "Act", "action", b),
+	))
+}
+
+func TestRaceFirstFinished(t *testing.T) {
+	R, S, F := b3.RUNNING, b3.SUCCESS, b3.FAILURE
+	cases := []struct {
+		name string
+		a, b string
+		want []b3.Status
+	}{
+		{"success wins", `{"n":2}`, `{"n":0}`, []b3.Status{R, R, S}},
+		{"failure wins", `{"n":0}`, `{"n":1,"result":2}`, []b3.Status{R, F}},
+		{"error wins", `{"n":-1,"result":4}`, `{"n":0}`, []b3.Status{b3.ERROR}},
+	}
+	for _, c := range cases {
+		for _, cooperative := range []bool{false, true} {
+			tt := raceTree(t, cooperative, c.a, c.b)
+			if got := runUntilDone(tt); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%s (cooperative %v): statuses %v, want %v", c.name, cooperative, got, c.want)
+			}
+			for _, id := range []string{"a", "b"} {
+				if tt.rec.count("open:"+id) != tt.rec.count("close:"+id) {
+					t.Errorf("%s (cooperative %v): %s left open", c.name, cooperative, id)
+				}
+			}
+		}
+	}
+}
+
+func TestRaceTicksOncePerTreeTick(t *testing.T) {
+	for _, cooperative := range []bool{false, true} {
+		tt := raceTree(t, cooperative, `{"n":0}`, `{"n":0}`)
+		for i := 0; i < 3; i++ {
+			if status := tt.run(); status != b3.RUNNING {
+				t.Fatalf("tick %d: status = %v", i, status)
+			}
+		}
+		if a, b := tt.rec.count("tick:a"), tt.rec.count("tick:b"); a != 3 || b != 3 {
+			t.Errorf("cooperative %v: a ticked %d and b %d times, want 3", cooperative, a, b)
+		}
+	}
+}
+
+func TestRaceCooperativeStopsAtWinner(t *testing.T) {
+	tt := raceTree(t, true, `{"n":-1}`, `{"n":0}`)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "open:a tick:a close:a" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestRaceClosedByParent(t *testing.T) {
+	for _, cooperative := range []bool{false, true} {
+		props := `{}`
+		if cooperative {
+			props = `{"cooperative":true}`
+		}
+		tt := newTestTree(t, treeJSON("root",
+			node("root", "Priority", "composite", "", "stop", "r"),
+			node("stop", "Check", "condition", `{"key":"$stop"}`),
+			node("r", "Race", "composite", props, "a", "b"),
+			node("a", "Act", "action", `{"n":0}`),
+			node("b", "Act", "action", `{"n":0}`),
+		))
+		tt.run()
+		tt.board.SetMem("stop", true)
+		if status := tt.run(); status != b3.SUCCESS {
+			t.Fatalf("status = %v", status)
+		}
+		if a, b := tt.rec.count("close:a"), tt.rec.count("close:b"); a != 1 || b != 1 {
+			t.Errorf("cooperative %v: a closed %d and b %d times, want 1", cooperative, a, b)
+		}
+	}
+}
+
+func TestRaceCancelsBlockingChildren(t *testing.T) {
+	tt := newTestTree(t, treeJSON("r",
+		node("r", "Race", "composite", "", "s", "a"),
+		node("s", "Sequence", "composite", "", "b"),
+		node("b", "Act", "action", `{"n":0}`),
+		node("a", "Act", "action", `{"n":-1,"result":2}`),
+	))
+	done := make(chan []b3.Status)
+	go func() { done <- runUntilDone(tt) }()
+	select {
+	case got := <-done:
+		//a结束时取消阻塞的Sequence
+		if want := []b3.Status{b3.FAILURE}; !reflect.DeepEqual(got, want) {
+			t.Errorf("statuses %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Race did not cancel the blocking Sequence")
+	}
+	if n := tt.rec.count("close:b"); n != 1 {
+		t.Errorf("b closed %d times", n)
+	}
+}
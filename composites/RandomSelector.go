@@ -0,0 +1,43 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * RandomSelector ticks its children in a random order, shuffled every time
+ * the node opens, until one of them does not return FAILURE. Like
+ * `RandomSequence` it keeps the order in the node memory while a child is
+ * RUNNING.
+ *
+ * @module b3
+ * @class RandomSelector
+ * @extends Composite
+**/
+type RandomSelector struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *RandomSelector) OnOpen(tick core.Ticker) {
+	openRandomOrder(tick, &p.Composite, tick.Rand().Perm(p.GetChildCount()))
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (p *RandomSelector) OnTick(tick core.Ticker) b3.Status {
+	return tickRandomOrder(tick, &p.Composite, b3.FAILURE)
+}
+
+func (p *RandomSelector) GetClass() string {
+	return b3.RANDOMSELECTOR
+}
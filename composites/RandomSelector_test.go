@@ -0,0 +1,28 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+func TestRandomSelectorStopsAtSuccess(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		tt := randomTree(t, "RandomSelector", "", seed, `{"n":-1,"result":2}`, `{"n":-1}`, `{"n":-1,"result":2}`)
+		if status := tt.run(); status != b3.SUCCESS {
+			t.Fatalf("seed %d: status = %v", seed, status)
+		}
+		order := tickOrder(tt.rec.take())
+		if order[len(order)-1] != "b" {
+			t.Errorf("seed %d: order = %v, want it to stop at b", seed, order)
+		}
+	}
+}
+
+func TestRandomSelectorAllFail(t *testing.T) {
+	tt := randomTree(t, "RandomSelector", "", 0, `{"n":-1,"result":2}`, `{"n":-1,"result":2}`, `{"n":-1,"result":4}`)
+	status := tt.run()
+	if order := tickOrder(tt.rec.take()); len(order) == 3 && status != b3.FAILURE || len(order) < 3 && status != b3.ERROR {
+		t.Errorf("status %v after %v", status, order)
+	}
+}
@@ -0,0 +1,71 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * RandomSequence ticks its children in a random order, shuffled every time
+ * the node opens, until one of them does not return SUCCESS. The order and
+ * the running child are kept in the node memory, so a RUNNING child is
+ * resumed on the next tick without shuffling again.
+ *
+ * The random order comes from `tick.Rand()`, seed the blackboard of the
+ * agent to replay it.
+ *
+ * @module b3
+ * @class RandomSequence
+ * @extends Composite
+**/
+type RandomSequence struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *RandomSequence) OnOpen(tick core.Ticker) {
+	openRandomOrder(tick, &s.Composite, tick.Rand().Perm(s.GetChildCount()))
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *RandomSequence) OnTick(tick core.Ticker) b3.Status {
+	return tickRandomOrder(tick, &s.Composite, b3.SUCCESS)
+}
+
+func (s *RandomSequence) GetClass() string {
+	return b3.RANDOMSEQUENCE
+}
+
+//打开时保存打乱后的顺序,从第一个开始
+func openRandomOrder(tick core.Ticker, c *core.Composite, order []int) {
+	tick.Blackboard().Set("order", order, tick.GetTree().GetID(), c.GetID())
+	tick.Blackboard().Set("runningChild", 0, tick.GetTree().GetID(), c.GetID())
+}
+
+//按保存的顺序执行子节点,子节点返回next时继续执行下一个
+func tickRandomOrder(tick core.Ticker, c *core.Composite, next b3.Status) b3.Status {
+	order, _ := tick.Blackboard().Get("order", tick.GetTree().GetID(), c.GetID()).([]int)
+	if len(order) != c.GetChildCount() {
+		order = tick.Rand().Perm(c.GetChildCount())
+		openRandomOrder(tick, c, order)
+	}
+	for i := tick.Blackboard().GetInt("runningChild", tick.GetTree().GetID(), c.GetID()); i < len(order); i++ {
+		status := c.GetChild(order[i]).Execute(tick)
+		if status == b3.RUNNING {
+			tick.Blackboard().Set("runningChild", i, tick.GetTree().GetID(), c.GetID())
+		}
+		if status != next {
+			return status
+		}
+	}
+	return next
+}
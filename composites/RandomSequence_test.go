@@ -0,0 +1,83 @@
+package composites_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+//三个子节点的随机组合节点,黑板使用固定的种子
+func randomTree(t *testing.T, name, props string, seed int64, a, b, c string) *testTree {
+	t.Helper()
+	tt := newTestTree(t, treeJSON("r",
+		node("r", name, "composite", props, "a", "b", "c"),
+		node("a", "Act", "action", a),
+		node("b", "Act", "action", b),
+		node("c", "Act", "action", c),
+	))
+	tt.board.SetSeed(seed)
+	return tt
+}
+
+//tick事件里子节点的顺序
+func tickOrder(events string) []string {
+	var order []string
+	for _, e := range strings.Fields(events) {
+		if strings.HasPrefix(e, "tick:") {
+			order = append(order, strings.TrimPrefix(e, "tick:"))
+		}
+	}
+	return order
+}
+
+func TestRandomSequenceSeeded(t *testing.T) {
+	orders := make(map[string]bool)
+	for seed := int64(0); seed < 20; seed++ {
+		first := randomTree(t, "RandomSequence", "", seed, `{"n":-1}`, `{"n":-1}`, `{"n":-1}`)
+		again := randomTree(t, "RandomSequence", "", seed, `{"n":-1}`, `{"n":-1}`, `{"n":-1}`)
+		if status := first.run(); status != b3.SUCCESS {
+			t.Fatalf("status = %v", status)
+		}
+		again.run()
+		order, replay := tickOrder(first.rec.take()), tickOrder(again.rec.take())
+		if !reflect.DeepEqual(order, replay) || len(order) != 3 {
+			t.Fatalf("seed %d: order %v, replay %v", seed, order, replay)
+		}
+		orders[strings.Join(order, "")] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("20 seeds gave the orders %v", orders)
+	}
+}
+
+func TestRandomSequenceResumesRunningChild(t *testing.T) {
+	tt := randomTree(t, "RandomSequence", "", 1, `{"n":1}`, `{"n":1}`, `{"n":1}`)
+	var order []string
+	for i := 0; i < 3; i++ {
+		if status := tt.run(); status != b3.RUNNING {
+			t.Fatalf("tick %d: status = %v", i, status)
+		}
+		order = append(order, tickOrder(tt.rec.take())...)
+	}
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	order = append(order, tickOrder(tt.rec.take())...)
+	//每个子节点RUNNING一次后成功,运行中的子节点不会重新打乱
+	if len(order) != 6 || order[0] != order[1] || order[2] != order[3] || order[4] != order[5] {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestRandomSequenceFailure(t *testing.T) {
+	tt := randomTree(t, "RandomSequence", "", 3, `{"n":-1}`, `{"n":-1,"result":2}`, `{"n":-1}`)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Errorf("status = %v", status)
+	}
+	order := tickOrder(tt.rec.take())
+	if order[len(order)-1] != "b" {
+		t.Errorf("order = %v, want it to stop at b", order)
+	}
+}
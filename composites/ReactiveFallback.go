@@ -0,0 +1,50 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * ReactiveFallback ticks its children from the first one every tick, like
+ * Priority, so a higher priority child is tried again while a lower one is
+ * RUNNING. When a child before the running one succeeds or starts running,
+ * the running child is halted: it and its open descendants are closed,
+ * calling their `close` callbacks.
+ *
+ *     ReactiveFallback
+ *       Sequence
+ *         EnemyInSight
+ *         Attack        <- takes over as soon as an enemy is seen
+ *       Patrol          <- halted then
+ *
+ * @module b3
+ * @class ReactiveFallback
+ * @extends Composite
+**/
+type ReactiveFallback struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *ReactiveFallback) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), p.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (p *ReactiveFallback) OnTick(tick core.Ticker) b3.Status {
+	return tickReactive(tick, &p.Composite, b3.FAILURE)
+}
+
+func (p *ReactiveFallback) GetClass() string {
+	return b3.REACTIVEFALLBACK
+}
@@ -0,0 +1,44 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+func TestReactiveFallbackHaltsLowerPriority(t *testing.T) {
+	tt := newTestTree(t, treeJSON("f",
+		node("f", "ReactiveFallback", "composite", "", "seen", "patrol"),
+		node("seen", "Check", "condition", `{"key":"$seen"}`),
+		node("patrol", "Act", "action", `{"n":0}`),
+	))
+	for i := 0; i < 2; i++ {
+		if status := tt.run(); status != b3.RUNNING {
+			t.Fatalf("tick %d: status = %v", i, status)
+		}
+	}
+	if events := tt.rec.take(); events != "check:seen open:patrol tick:patrol check:seen tick:patrol" {
+		t.Errorf("events = %q", events)
+	}
+	tt.board.SetMem("seen", true)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:seen close:patrol" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestReactiveFallbackAllFail(t *testing.T) {
+	tt := newTestTree(t, treeJSON("f",
+		node("f", "ReactiveFallback", "composite", "", "a", "b"),
+		node("a", "Act", "action", `{"n":-1,"result":2}`),
+		node("b", "Act", "action", `{"n":-1,"result":2}`),
+	))
+	if status := tt.run(); status != b3.FAILURE {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "open:a tick:a close:a open:b tick:b close:b" {
+		t.Errorf("events = %q", events)
+	}
+}
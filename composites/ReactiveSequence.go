@@ -0,0 +1,74 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * ReactiveSequence ticks its children from the first one every tick, like
+ * Sequence, so the conditions before a RUNNING child are checked again on
+ * every tick. When one of them fails, the child that was running is halted:
+ * it and its open descendants are closed, calling their `close` callbacks,
+ * before the failure is returned.
+ *
+ *     ReactiveSequence
+ *       HasTarget
+ *       MoveToTarget    <- halted as soon as HasTarget fails
+ *
+ * @module b3
+ * @class ReactiveSequence
+ * @extends Composite
+**/
+type ReactiveSequence struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *ReactiveSequence) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *ReactiveSequence) OnTick(tick core.Ticker) b3.Status {
+	return tickReactive(tick, &s.Composite, b3.SUCCESS)
+}
+
+func (s *ReactiveSequence) GetClass() string {
+	return b3.REACTIVESEQUENCE
+}
+
+//从第一个子节点开始执行,子节点返回next时继续,停在前面的子节点时关闭上次运行的子节点
+func tickReactive(tick core.Ticker, c *core.Composite, next b3.Status) b3.Status {
+	running, ok := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), c.GetID()).(int)
+	if !ok {
+		running = -1
+	}
+	status := next
+	i := 0
+	for ; i < c.GetChildCount(); i++ {
+		status = c.GetChild(i).Execute(tick)
+		if status != next {
+			break
+		}
+	}
+	if running > i && running < c.GetChildCount() {
+		core.CloseNode(tick, c.GetChild(running))
+	}
+	if status == b3.RUNNING {
+		running = i
+	} else {
+		running = -1
+	}
+	tick.Blackboard().Set("runningChild", running, tick.GetTree().GetID(), c.GetID())
+	return status
+}
@@ -0,0 +1,55 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+func TestReactiveSequenceHaltsRunningChild(t *testing.T) {
+	tt := newTestTree(t, treeJSON("s",
+		node("s", "ReactiveSequence", "composite", "", "ok", "a"),
+		node("ok", "Check", "condition", `{"key":"$ok"}`),
+		node("a", "Act", "action", `{"n":0}`),
+	))
+	tt.board.SetMem("ok", true)
+	for i := 0; i < 2; i++ {
+		if status := tt.run(); status != b3.RUNNING {
+			t.Fatalf("tick %d: status = %v", i, status)
+		}
+	}
+	if events := tt.rec.take(); events != "check:ok open:a tick:a check:ok tick:a" {
+		t.Errorf("events = %q", events)
+	}
+	tt.board.SetMem("ok", false)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:ok close:a" {
+		t.Errorf("events = %q", events)
+	}
+	//重新开始时a重新打开
+	tt.board.SetMem("ok", true)
+	tt.run()
+	if events := tt.rec.take(); events != "check:ok open:a tick:a" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestReactiveSequenceSucceeds(t *testing.T) {
+	tt := newTestTree(t, treeJSON("s",
+		node("s", "ReactiveSequence", "composite", "", "a", "b"),
+		node("a", "Act", "action", `{"n":-1}`),
+		node("b", "Act", "action", `{"n":1}`),
+	))
+	if status := tt.run(); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	//每次tick都从a开始
+	if n := tt.rec.count("tick:a"); n != 2 {
+		t.Errorf("a ticked %d times", n)
+	}
+}
@@ -0,0 +1,58 @@
+package composites
+
+import (
+	"fmt"
+	"math"
+)
+
+/**
+ * A response curve maps a normalized input in [0, 1] to a utility in
+ * [0, 1]:
+ *
+ * - **linear** `slope * x + offset`
+ * - **quadratic** `slope * x^exponent + offset`
+ * - **logistic** `1 / (1 + e^(-steepness * (x - midpoint))) + offset`
+ *
+ * @class ResponseCurve
+**/
+type ResponseCurve struct {
+	Kind      string
+	Slope     float64
+	Offset    float64
+	Exponent  float64
+	Steepness float64
+	Midpoint  float64
+}
+
+const (
+	CurveLinear    = "linear"
+	CurveQuadratic = "quadratic"
+	CurveLogistic  = "logistic"
+)
+
+//使用默认参数的曲线
+func NewResponseCurve(kind string) (ResponseCurve, error) {
+	if kind == "" {
+		kind = CurveLinear
+	}
+	switch kind {
+	case CurveLinear, CurveQuadratic, CurveLogistic:
+	default:
+		return ResponseCurve{}, fmt.Errorf("unknown curve %q", kind)
+	}
+	return ResponseCurve{Kind: kind, Slope: 1, Exponent: 2, Steepness: 10, Midpoint: 0.5}, nil
+}
+
+//计算x的效用,结果限制在0到1之间
+func (c ResponseCurve) Evaluate(x float64) float64 {
+	var y float64
+	switch c.Kind {
+	case CurveQuadratic:
+		y = c.Slope*math.Pow(x, c.Exponent) + c.Offset
+	case CurveLogistic:
+		y = 1/(1+math.Exp(-c.Steepness*(x-c.Midpoint))) + c.Offset
+	default:
+		y = c.Slope*x + c.Offset
+	}
+	return math.Max(0, math.Min(1, y))
+}
@@ -19,7 +19,7 @@ type Sequence struct {
 **/
 func (s *Sequence) OnTick(tick core.Ticker) b3.Status {
 	cancelCtx := context.Background()
-	if ctx := s.GetValueFromAncestor("cancelCtx", tick.Blackboard()); ctx != nil {
+	if ctx := core.AncestorValue(tick, s, "cancelCtx"); ctx != nil {
 		cancelCtx = ctx.(context.Context)
 	}
 	for i := 0; i < s.GetChildCount(); i++ {
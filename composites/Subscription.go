@@ -31,14 +31,14 @@ func (s *Subscription) OnTick(tick core.Ticker) b3.Status {
 		return b3.FAILURE
 	}
 	client := s.ClientCreator(tick)
-	tick.Blackboard().Set("subClient", client, s.GetTreeID(), s.GetID())
+	tick.Blackboard().Set("subClient", client, tick.GetTree().GetID(), s.GetID())
 	for i := 0; i < s.GetChildCount(); i++ {
 		var status = s.GetChild(i).Execute(tick)
 		if status != b3.SUCCESS {
 			return status
 		}
 	}
-	ctxValue := s.GetValueFromAncestor("cancelCtx", tick.Blackboard())
+	ctxValue := core.AncestorValue(tick, s, "cancelCtx")
 	if ctxValue == nil {
 		return b3.FAILURE
 	}
@@ -0,0 +1,177 @@
+package composites
+
+import (
+	"fmt"
+	"reflect"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * Switch reads a blackboard value and runs the child whose case label
+ * matches it, instead of a Priority of conditions:
+ *
+ *     Switch {"key": "state", "cases": ["idle", "patrol", ["flee", "hide"]]}
+ *       Idle
+ *       Patrol
+ *       Hide
+ *       Attack    <- the default child
+ *
+ * `cases` holds one label per child, in the order of the children. A label
+ * is a string, a number or a boolean, or an array of them to match any.
+ * Numbers match any numeric type, and a string also matches a value whose
+ * `String()` returns it, such as an enum. A child after the last case is
+ * the default child, run when no label matches. Without default child the
+ * Switch fails when no label matches.
+ *
+ * `scope` is `global` (default) or `tree`. When the value changes while a
+ * child is RUNNING, that child is closed before the new case runs.
+ *
+ * @module b3
+ * @class Switch
+ * @extends Composite
+**/
+type Switch struct {
+	core.Composite
+
+	/**
+	 * The blackboard key.
+	 * @property {String} key
+	**/
+	Key string `b3:"key,required"`
+
+	/**
+	 * The blackboard scope of the key: global or tree.
+	 * @property {String} scope
+	**/
+	Scope string `b3:"scope,default=global"`
+
+	/**
+	 * The case label of each child.
+	 * @property {Array} cases
+	**/
+	Cases []interface{} `b3:"cases,required"`
+
+	value core.Value
+}
+
+/**
+ * Initialization method.
+ * @method Initialize
+ * @construCtor
+**/
+func (s *Switch) Initialize(setting *config.BTNodeCfg) {
+	s.Composite.Initialize(setting)
+	if s.Scope != core.ScopeGlobal && s.Scope != core.ScopeTree {
+		panic(fmt.Sprintf("Switch: unknown scope %q", s.Scope))
+	}
+	for i, label := range s.Cases {
+		if err := checkCaseLabel(label, true); err != nil {
+			panic(fmt.Sprintf("Switch cases[%d]: %v", i, err))
+		}
+	}
+	s.value = core.RefValue(s.Key, s.Scope)
+}
+
+func checkCaseLabel(label interface{}, nested bool) error {
+	switch l := label.(type) {
+	case string, bool, float64:
+		return nil
+	case []interface{}:
+		if nested {
+			for _, item := range l {
+				if err := checkCaseLabel(item, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid case label %v", label)
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *Switch) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *Switch) OnTick(tick core.Ticker) b3.Status {
+	childNum := s.GetChildCount()
+	if childNum < len(s.Cases) || childNum > len(s.Cases)+1 {
+		return b3.ERROR
+	}
+	index := s.Match(s.value.Get(tick))
+	if index < 0 && childNum > len(s.Cases) {
+		index = len(s.Cases)
+	}
+
+	running, _ := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), s.GetID()).(int)
+	if running >= 0 && running != index && running < childNum {
+		core.CloseNode(tick, s.GetChild(running))
+	}
+	if index < 0 {
+		tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+		return b3.FAILURE
+	}
+	status := s.GetChild(index).Execute(tick)
+	if status != b3.RUNNING {
+		index = -1
+	}
+	tick.Blackboard().Set("runningChild", index, tick.GetTree().GetID(), s.GetID())
+	return status
+}
+
+//匹配的case序号,没有匹配时返回-1
+func (s *Switch) Match(value interface{}) int {
+	for i, label := range s.Cases {
+		if items, ok := label.([]interface{}); ok {
+			for _, item := range items {
+				if caseMatch(item, value) {
+					return i
+				}
+			}
+		} else if caseMatch(label, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+func caseMatch(label, value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	switch l := label.(type) {
+	case string:
+		if v, ok := value.(fmt.Stringer); ok {
+			return v.String() == l
+		}
+		return rv.Kind() == reflect.String && rv.String() == l
+	case bool:
+		return rv.Kind() == reflect.Bool && rv.Bool() == l
+	case float64:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()) == l
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()) == l
+		case reflect.Float32, reflect.Float64:
+			return rv.Float() == l
+		}
+	}
+	return false
+}
+
+func (s *Switch) GetClass() string {
+	return b3.SWITCH
+}
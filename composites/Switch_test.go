@@ -0,0 +1,109 @@
+package composites_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/composites"
+)
+
+type mood int
+
+func (m mood) String() string {
+	return [...]string{"calm", "angry"}[m]
+}
+
+func TestSwitchMatch(t *testing.T) {
+	var cases []interface{}
+	if err := json.Unmarshal([]byte(`["idle", 2, true, ["flee", "hide"], "angry"]`), &cases); err != nil {
+		t.Fatal(err)
+	}
+	s := &composites.Switch{Cases: cases}
+	tests := []struct {
+		value interface{}
+		want  int
+	}{
+		{"idle", 0},
+		{2, 1},
+		{int64(2), 1},
+		{uint8(2), 1},
+		{2.0, 1},
+		{"2", -1},
+		{true, 2},
+		{false, -1},
+		{"hide", 3},
+		{mood(1), 4},
+		{mood(0), -1},
+		{nil, -1},
+	}
+	for _, tc := range tests {
+		if got := s.Match(tc.value); got != tc.want {
+			t.Errorf("Match(%#v) = %d, want %d", tc.value, got, tc.want)
+		}
+	}
+}
+
+//idle和patrol两个case,withDefault时加上默认的子节点
+func switchTree(t *testing.T, props string, withDefault bool) *testTree {
+	t.Helper()
+	children := []string{"idle", "patrol"}
+	nodes := []string{
+		node("idle", "Act", "action", `{"n":0}`),
+		node("patrol", "Act", "action", `{"n":0}`),
+	}
+	if withDefault {
+		children = append(children, "other")
+		nodes = append(nodes, node("other", "Act", "action", `{"n":-1,"result":2}`))
+	}
+	nodes = append(nodes, node("s", "Switch", "composite", props, children...))
+	return newTestTree(t, treeJSON("s", nodes...))
+}
+
+func TestSwitchClosesRunningCase(t *testing.T) {
+	tt := switchTree(t, `{"key":"state","cases":["idle","patrol"]}`, true)
+	tt.board.SetMem("state", "idle")
+	tt.run()
+	tt.run()
+	if events := tt.rec.take(); events != "open:idle tick:idle tick:idle" {
+		t.Errorf("events = %q", events)
+	}
+	tt.board.SetMem("state", "patrol")
+	if status := tt.run(); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "close:idle open:patrol tick:patrol" {
+		t.Errorf("events = %q", events)
+	}
+	//没有匹配时执行默认的子节点
+	tt.board.SetMem("state", "sleep")
+	if status := tt.run(); status != b3.FAILURE {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "close:patrol open:other tick:other close:other" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestSwitchWithoutDefault(t *testing.T) {
+	tt := switchTree(t, `{"key":"state","cases":["idle","patrol"]}`, false)
+	tt.board.SetMem("state", "patrol")
+	tt.run()
+	tt.board.SetMem("state", "sleep")
+	if status := tt.run(); status != b3.FAILURE {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "open:patrol tick:patrol close:patrol" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestSwitchTreeScope(t *testing.T) {
+	tt := switchTree(t, `{"key":"state","scope":"tree","cases":["idle","patrol"]}`, false)
+	tt.board.SetMem("state", "idle")
+	tt.board.SetTree("state", "patrol", tt.GetID())
+	tt.run()
+	if events := tt.rec.take(); events != "open:patrol tick:patrol" {
+		t.Errorf("events = %q", events)
+	}
+}
@@ -0,0 +1,236 @@
+package composites
+
+import (
+	"fmt"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+//可以给出效用分数的子节点
+type Scorer interface {
+	Score(tick core.Ticker) float64
+}
+
+/**
+ * UtilitySelector scores all its children every tick and runs the highest
+ * scoring one. If that child fails the next best is tried, like Priority.
+ * Children scoring 0 or less are never run, if none is left it returns
+ * FAILURE.
+ *
+ * The score of a child comes from its entry in `scorers`, or, without an
+ * entry, from the child itself when it implements `Scorer`. An entry maps
+ * an input, usually a blackboard value, through a response curve:
+ *
+ *     "scorers": [
+ *       {"input": "$hunger", "max": 100, "curve": "quadratic"},
+ *       {"input": "$enemyDistance", "min": 2, "max": 20, "curve": "logistic",
+ *        "steepness": -8, "weight": 1.5},
+ *       {}
+ *     ]
+ *
+ * The input is normalized from [min, max] (default [0, 1]) to [0, 1], passed
+ * through the curve (see `ResponseCurve`, default linear) and multiplied by
+ * `weight`. Every field may be a blackboard reference.
+ *
+ * To avoid flip-flopping between children with close scores, the child
+ * chosen last keeps running until another one beats it by more than
+ * `hysteresis`. A running child that is not chosen any more is closed.
+ *
+ * The last scores are kept in the node memory as `scores`.
+ *
+ * @module b3
+ * @class UtilitySelector
+ * @extends Composite
+**/
+type UtilitySelector struct {
+	core.Composite
+
+	/**
+	 * The scorer of each child, in the order of the children.
+	 * @property {Array} scorers
+	**/
+	Scorers []map[string]core.Value `b3:"scorers"`
+
+	/**
+	 * How much a child must beat the current one by to replace it.
+	 * @property {Number} hysteresis
+	**/
+	Hysteresis float64 `b3:"hysteresis,min=0,default=0.1"`
+}
+
+var scorerFields = map[string]float64{
+	"min":       0,
+	"max":       1,
+	"weight":    1,
+	"slope":     1,
+	"offset":    0,
+	"exponent":  2,
+	"steepness": 10,
+	"midpoint":  0.5,
+}
+
+/**
+ * Initialization method.
+ * @method Initialize
+ * @construCtor
+**/
+func (p *UtilitySelector) Initialize(setting *config.BTNodeCfg) {
+	p.Composite.Initialize(setting)
+	for i, scorer := range p.Scorers {
+		if err := checkScorer(scorer); err != nil {
+			panic(fmt.Sprintf("UtilitySelector scorers[%d]: %v", i, err))
+		}
+	}
+}
+
+func checkScorer(scorer map[string]core.Value) error {
+	if len(scorer) == 0 {
+		return nil
+	}
+	for key := range scorer {
+		if _, ok := scorerFields[key]; !ok && key != "input" && key != "curve" {
+			return fmt.Errorf("unknown field %s", key)
+		}
+	}
+	if _, ok := scorer["input"]; !ok {
+		return fmt.Errorf("input is required")
+	}
+	//字面值不需要tick就能读取
+	if curve, ok := scorer["curve"]; ok && !curve.IsRef() {
+		if _, err := NewResponseCurve(curve.String(nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *UtilitySelector) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), p.GetID())
+	tick.Blackboard().Set("current", -1, tick.GetTree().GetID(), p.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (p *UtilitySelector) OnTick(tick core.Ticker) b3.Status {
+	childNum := p.GetChildCount()
+	scores, order := p.buffers(tick, childNum)
+	for i := 0; i < childNum; i++ {
+		scores[i] = p.score(tick, i)
+		order[i] = i
+	}
+	sortByScore(order, scores)
+
+	//上次选中的子节点没有被明显超过时排在最前
+	current, ok := tick.Blackboard().Get("current", tick.GetTree().GetID(), p.GetID()).(int)
+	if ok && current >= 0 && current < childNum && scores[current] > 0 && scores[order[0]]-scores[current] <= p.Hysteresis {
+		for i, c := range order {
+			if c == current {
+				copy(order[1:i+1], order[:i])
+				order[0] = current
+				break
+			}
+		}
+	}
+
+	running, _ := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), p.GetID()).(int)
+	for _, i := range order {
+		if scores[i] <= 0 {
+			break
+		}
+		if running >= 0 && running != i && running < childNum {
+			core.CloseNode(tick, p.GetChild(running))
+		}
+		running = -1
+		status := p.GetChild(i).Execute(tick)
+		if status == b3.RUNNING {
+			running = i
+		}
+		if status != b3.FAILURE {
+			tick.Blackboard().Set("runningChild", running, tick.GetTree().GetID(), p.GetID())
+			tick.Blackboard().Set("current", i, tick.GetTree().GetID(), p.GetID())
+			return status
+		}
+	}
+	if running >= 0 && running < childNum {
+		core.CloseNode(tick, p.GetChild(running))
+	}
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), p.GetID())
+	return b3.FAILURE
+}
+
+//分数和排序用的切片保存在节点内存里,每次tick重复使用
+func (p *UtilitySelector) buffers(tick core.Ticker, childNum int) ([]float64, []int) {
+	scores, _ := tick.Blackboard().Get("scores", tick.GetTree().GetID(), p.GetID()).([]float64)
+	order, _ := tick.Blackboard().Get("order", tick.GetTree().GetID(), p.GetID()).([]int)
+	if len(scores) != childNum || len(order) != childNum {
+		scores, order = make([]float64, childNum), make([]int, childNum)
+		tick.Blackboard().Set("scores", scores, tick.GetTree().GetID(), p.GetID())
+		tick.Blackboard().Set("order", order, tick.GetTree().GetID(), p.GetID())
+	}
+	return scores, order
+}
+
+//按分数从高到低的稳定插入排序,子节点不多,也不需要分配内存
+func sortByScore(order []int, scores []float64) {
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && scores[order[j]] > scores[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+}
+
+//子节点的效用分数
+func (p *UtilitySelector) score(tick core.Ticker, index int) float64 {
+	if index < len(p.Scorers) && len(p.Scorers[index]) > 0 {
+		return evaluateScorer(tick, p.Scorers[index])
+	}
+	if scorer, ok := p.GetChild(index).(Scorer); ok {
+		return scorer.Score(tick)
+	}
+	return 0
+}
+
+func evaluateScorer(tick core.Ticker, scorer map[string]core.Value) float64 {
+	field := func(key string) float64 {
+		if v, ok := scorer[key]; ok {
+			return v.Float64(tick)
+		}
+		return scorerFields[key]
+	}
+	curve, err := NewResponseCurve(scorer["curve"].String(tick))
+	if err != nil {
+		return 0
+	}
+	curve.Slope = field("slope")
+	curve.Offset = field("offset")
+	curve.Exponent = field("exponent")
+	curve.Steepness = field("steepness")
+	curve.Midpoint = field("midpoint")
+
+	x := scorer["input"].Float64(tick)
+	min, max := field("min"), field("max")
+	if max != min {
+		x = (x - min) / (max - min)
+	}
+	if x < 0 {
+		x = 0
+	} else if x > 1 {
+		x = 1
+	}
+	return curve.Evaluate(x) * field("weight")
+}
+
+func (p *UtilitySelector) GetClass() string {
+	return b3.UTILITYSELECTOR
+}
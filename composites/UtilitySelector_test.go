@@ -0,0 +1,103 @@
+package composites_test
+
+import (
+	"reflect"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/composites"
+)
+
+//两个子节点的UtilitySelector,分数来自黑板的$a和$b
+func utilityTree(t *testing.T, a, b string) *testTree {
+	t.Helper()
+	return newTestTree(t, treeJSON("u",
+		node("u", "UtilitySelector", "composite", `{"scorers":[{"input":"$a"},{"input":"$b"}]}`, "a", "b"),
+		node("a", "Act", "action", a),
+		node("b", "Act", "action", b),
+	))
+}
+
+func (tt *testTree) scores(a, b float64) {
+	tt.board.SetMem("a", a)
+	tt.board.SetMem("b", b)
+}
+
+func TestUtilitySelectorHighestScore(t *testing.T) {
+	tt := utilityTree(t, `{"n":-1}`, `{"n":-1}`)
+	tt.scores(0.3, 0.8)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if order := tickOrder(tt.rec.take()); !reflect.DeepEqual(order, []string{"b"}) {
+		t.Errorf("order = %v", order)
+	}
+	u := tt.GetRoot().(*composites.UtilitySelector)
+	scores, _ := tt.board.Get("scores", tt.GetID(), u.GetID()).([]float64)
+	if !reflect.DeepEqual(scores, []float64{0.3, 0.8}) {
+		t.Errorf("scores = %v", scores)
+	}
+}
+
+func TestUtilitySelectorFallsBack(t *testing.T) {
+	tt := utilityTree(t, `{"n":-1}`, `{"n":-1,"result":2}`)
+	tt.scores(0.3, 0.8)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Fatalf("status = %v", status)
+	}
+	if order := tickOrder(tt.rec.take()); !reflect.DeepEqual(order, []string{"b", "a"}) {
+		t.Errorf("order = %v", order)
+	}
+	//分数为0的子节点不会执行
+	tt.scores(0, 0.8)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Fatalf("status = %v", status)
+	}
+	if order := tickOrder(tt.rec.take()); !reflect.DeepEqual(order, []string{"b"}) {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestUtilitySelectorHysteresis(t *testing.T) {
+	tt := utilityTree(t, `{"n":0}`, `{"n":0}`)
+	tt.scores(0.6, 0.5)
+	tt.run()
+	//b没有超过a的分数加上hysteresis,a继续运行
+	tt.scores(0.6, 0.65)
+	tt.run()
+	if events := tt.rec.take(); events != "open:a tick:a tick:a" {
+		t.Errorf("events = %q", events)
+	}
+	tt.scores(0.6, 0.8)
+	tt.run()
+	if events := tt.rec.take(); events != "close:a open:b tick:b" {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestUtilitySelectorForgetsLastChoice(t *testing.T) {
+	tt := utilityTree(t, `{"n":-1}`, `{"n":-1}`)
+	tt.scores(0.6, 0.5)
+	tt.run()
+	tt.rec.take()
+	//重新打开后上次的选择不再有hysteresis的优势
+	tt.scores(0.5, 0.55)
+	tt.run()
+	if order := tickOrder(tt.rec.take()); !reflect.DeepEqual(order, []string{"b"}) {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestUtilitySelectorReusesScores(t *testing.T) {
+	tt := utilityTree(t, `{"n":0}`, `{"n":0}`)
+	tt.scores(0.3, 0.8)
+	tt.run()
+	u := tt.GetRoot().(*composites.UtilitySelector)
+	first, _ := tt.board.Get("scores", tt.GetID(), u.GetID()).([]float64)
+	tt.scores(0.4, 0.8)
+	tt.run()
+	scores, _ := tt.board.Get("scores", tt.GetID(), u.GetID()).([]float64)
+	if &scores[0] != &first[0] || scores[0] != 0.4 {
+		t.Errorf("scores = %v, want the slice of the first tick updated", scores)
+	}
+}
@@ -0,0 +1,95 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * WeightedRandomSelector is a `RandomSelector` whose order is drawn by
+ * weight: every time the node opens, the first child is picked with a
+ * probability proportional to its weight, the second among the remaining
+ * children, and so on.
+ *
+ * `weights` holds one weight per child, in the order of the children.
+ * Missing weights count as 1, children with a weight of 0 are tried last.
+ *
+ * @module b3
+ * @class WeightedRandomSelector
+ * @extends Composite
+**/
+type WeightedRandomSelector struct {
+	core.Composite
+
+	/**
+	 * The weight of each child.
+	 * @property {Array} weights
+	**/
+	Weights []float64 `b3:"weights"`
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (p *WeightedRandomSelector) OnOpen(tick core.Ticker) {
+	openRandomOrder(tick, &p.Composite, p.weightedOrder(tick))
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (p *WeightedRandomSelector) OnTick(tick core.Ticker) b3.Status {
+	return tickRandomOrder(tick, &p.Composite, b3.FAILURE)
+}
+
+//按权重不放回抽取子节点的顺序
+func (p *WeightedRandomSelector) weightedOrder(tick core.Ticker) []int {
+	childNum := p.GetChildCount()
+	weights := make([]float64, childNum)
+	remain := make([]int, childNum)
+	for i := range weights {
+		weights[i] = 1
+		if i < len(p.Weights) {
+			weights[i] = p.Weights[i]
+		}
+		if weights[i] < 0 {
+			weights[i] = 0
+		}
+		remain[i] = i
+	}
+
+	order := make([]int, 0, childNum)
+	for len(remain) > 0 {
+		var total float64
+		for _, c := range remain {
+			total += weights[c]
+		}
+		//剩下的权重都是0时按随机顺序
+		pick := tick.Rand().Intn(len(remain))
+		if total > 0 {
+			r := tick.Rand().Float64() * total
+			for i, c := range remain {
+				if weights[c] == 0 {
+					continue
+				}
+				pick = i
+				if r < weights[c] {
+					break
+				}
+				r -= weights[c]
+			}
+		}
+		order = append(order, remain[pick])
+		remain = append(remain[:pick], remain[pick+1:]...)
+	}
+	return order
+}
+
+func (p *WeightedRandomSelector) GetClass() string {
+	return b3.WEIGHTEDRANDOMSELECTOR
+}
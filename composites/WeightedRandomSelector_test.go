@@ -0,0 +1,41 @@
+package composites_test
+
+import (
+	"testing"
+)
+
+func TestWeightedRandomSelectorWeights(t *testing.T) {
+	fail := `{"n":-1,"result":2}`
+	for seed := int64(0); seed < 20; seed++ {
+		//c is the only child with a weight, b has a weight of 0 and comes last
+		tt := randomTree(t, "WeightedRandomSelector", `{"weights":[1,0,5]}`, seed, fail, fail, fail)
+		tt.run()
+		order := tickOrder(tt.rec.take())
+		if len(order) != 3 || order[2] != "b" {
+			t.Fatalf("seed %d: order = %v", seed, order)
+		}
+	}
+	//missing weights count as 1
+	firsts := make(map[string]int)
+	for seed := int64(0); seed < 200; seed++ {
+		tt := randomTree(t, "WeightedRandomSelector", `{"weights":[0]}`, seed, fail, fail, fail)
+		tt.run()
+		order := tickOrder(tt.rec.take())
+		if order[2] != "a" {
+			t.Fatalf("seed %d: order = %v", seed, order)
+		}
+		firsts[order[0]]++
+	}
+	if firsts["b"] < 60 || firsts["c"] < 60 {
+		t.Errorf("first children = %v", firsts)
+	}
+}
+
+func TestWeightedRandomSelectorAllZero(t *testing.T) {
+	fail := `{"n":-1,"result":2}`
+	tt := randomTree(t, "WeightedRandomSelector", `{"weights":[0,0,0]}`, 7, fail, fail, fail)
+	tt.run()
+	if order := tickOrder(tt.rec.take()); len(order) != 3 {
+		t.Errorf("order = %v", order)
+	}
+}
@@ -0,0 +1,85 @@
+package composites
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * WhileDoElse has two or three children: a condition, the body and the
+ * optional else branch. The condition is checked on every tick:
+ *
+ * - while it succeeds the body runs. When the body succeeds WhileDoElse
+ *   returns RUNNING and runs it again on the next tick, when it fails the
+ *   loop ends with FAILURE.
+ * - when it fails the loop ends: the else branch runs and its status is
+ *   returned, or SUCCESS without else branch.
+ *
+ * A branch that was RUNNING and is not chosen any more, because the
+ * condition changed, is closed. The running branch is kept in the node
+ * memory as `runningChild`.
+ *
+ * @module b3
+ * @class WhileDoElse
+ * @extends Composite
+**/
+type WhileDoElse struct {
+	core.Composite
+}
+
+/**
+ * Open method.
+ * @method open
+ * @param {b3.Tick} tick A tick instance.
+**/
+func (s *WhileDoElse) OnOpen(tick core.Ticker) {
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+}
+
+/**
+ * Tick method.
+ * @method tick
+ * @param {b3.Tick} tick A tick instance.
+ * @return {Constant} A state constant.
+**/
+func (s *WhileDoElse) OnTick(tick core.Ticker) b3.Status {
+	childNum := s.GetChildCount()
+	if childNum != 2 && childNum != 3 {
+		return b3.ERROR
+	}
+	running, _ := tick.Blackboard().Get("runningChild", tick.GetTree().GetID(), s.GetID()).(int)
+	var branch int
+	switch status := s.GetChild(0).Execute(tick); status {
+	case b3.SUCCESS:
+		branch = 1
+	case b3.FAILURE:
+		branch = 2
+	default:
+		return status
+	}
+	if running > 0 && running != branch {
+		core.CloseNode(tick, s.GetChild(running))
+	}
+
+	var status b3.Status
+	switch {
+	case branch < childNum:
+		status = s.GetChild(branch).Execute(tick)
+	default:
+		status = b3.SUCCESS
+	}
+	if status == b3.RUNNING {
+		tick.Blackboard().Set("runningChild", branch, tick.GetTree().GetID(), s.GetID())
+		return status
+	}
+	tick.Blackboard().Set("runningChild", -1, tick.GetTree().GetID(), s.GetID())
+	if branch == 1 && status == b3.SUCCESS {
+		//循环体成功时下次tick继续
+		return b3.RUNNING
+	}
+	return status
+}
+
+func (s *WhileDoElse) GetClass() string {
+	return b3.WHILEDOELSE
+}
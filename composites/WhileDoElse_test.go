@@ -0,0 +1,57 @@
+package composites_test
+
+import (
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+func TestWhileDoElseLoops(t *testing.T) {
+	tt := branchTree(t, "WhileDoElse", `{"n":-1}`, `{"n":-1,"result":2}`)
+	tt.board.SetMem("ok", true)
+	for i := 0; i < 2; i++ {
+		if status := tt.run(); status != b3.RUNNING {
+			t.Fatalf("tick %d: status = %v", i, status)
+		}
+	}
+	if n := tt.rec.count("tick:then"); n != 2 {
+		t.Errorf("body ticked %d times", n)
+	}
+	tt.board.SetMem("ok", false)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Errorf("else: status = %v", status)
+	}
+
+	tt = branchTree(t, "WhileDoElse", `{"n":-1,"result":2}`, "")
+	tt.board.SetMem("ok", true)
+	if status := tt.run(); status != b3.FAILURE {
+		t.Errorf("failed body: status = %v", status)
+	}
+	tt.board.SetMem("ok", false)
+	if status := tt.run(); status != b3.SUCCESS {
+		t.Errorf("no else: status = %v", status)
+	}
+}
+
+func TestWhileDoElseClosesBranch(t *testing.T) {
+	tt := branchTree(t, "WhileDoElse", `{"n":0}`, `{"n":0}`)
+	tt.board.SetMem("ok", true)
+	tt.run()
+	tt.run()
+	if events := tt.rec.take(); events != "check:ok open:then tick:then check:ok tick:then" {
+		t.Errorf("events = %q", events)
+	}
+	//条件改变时关闭运行的分支
+	tt.board.SetMem("ok", false)
+	if status := tt.run(); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	if events := tt.rec.take(); events != "check:ok close:then open:else tick:else" {
+		t.Errorf("events = %q", events)
+	}
+	tt.board.SetMem("ok", true)
+	tt.run()
+	if events := tt.rec.take(); events != "check:ok close:else open:then tick:then" {
+		t.Errorf("events = %q", events)
+	}
+}
@@ -24,13 +24,11 @@ func (this *BTNodeCfg) GetProperty(name string) float64 {
 	v, ok := this.Properties[name]
 	if !ok {
 		panic("GetProperty err ,no vlaue:" + name)
-		return 0
 	}
 	f64, fok := v.(float64)
 	if !fok {
 		fmt.Println("GetProperty err ,format not fload64:", name, v)
 		panic("GetProperty err ,format not fload64:" + name)
-		return 0
 	}
 	return f64
 }
@@ -59,7 +57,6 @@ func (this *BTNodeCfg) GetPropertyAsBool(name string) bool {
 		}
 		fmt.Println("GetProperty err ,format not bool:", name, v)
 		panic("GetProperty err ,format not bool:" + name)
-		return false
 	}
 	return b
 }
@@ -67,20 +64,19 @@ func (this *BTNodeCfg) GetPropertyAsString(name string) string {
 	v, ok := this.Properties[name]
 	if !ok {
 		panic("GetProperty err ,no vlaue:" + name)
-		return ""
 	}
 
 	str, fok := v.(string)
 	if !fok {
 		fmt.Println("GetProperty err ,format not string:", name, v)
 		panic("GetProperty err ,format not string:" + name)
-		return ""
 	}
 	return str
 }
 
 //树json类型
 type BTTreeCfg struct {
+	Version     string                 `json:"version,omitempty"`
 	ID          string                 `json:"id"`
 	Title       string                 `json:"title"`
 	Description string                 `json:"description"`
@@ -91,17 +87,28 @@ type BTTreeCfg struct {
 
 //加载
 func LoadTreeCfg(path string) (*BTTreeCfg, bool) {
+	tree, _, err := LoadTreeCfgReport(path)
+	if err != nil {
+		fmt.Println("fail:", err)
+		return nil, false
+	}
+	return tree, true
+}
 
+//加载并升级到最新版本,返回执行的迁移
+func LoadTreeCfgReport(path string) (*BTTreeCfg, []AppliedMigration, error) {
 	var tree BTTreeCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
-		fmt.Println("fail:", err)
-		return nil, false
+		return nil, nil, err
 	}
 	err = json.Unmarshal(file, &tree)
 	if err != nil {
-		fmt.Println("fail, ummarshal:", err, len(file))
-		return nil, false
+		return nil, nil, fmt.Errorf("ummarshal %s: %v", path, err)
+	}
+	applied, err := MigrateTree(&tree)
+	if err != nil {
+		return nil, applied, err
 	}
-	return &tree, true
+	return &tree, applied, nil
 }
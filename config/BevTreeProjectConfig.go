@@ -8,25 +8,52 @@ import (
 
 //工程json类型
 type BTProjectCfg struct {
-	ID     string      `json:"id"`
-	Select string      `json:"selectedTree"`
-	Scope  string      `json:"scope"`
-	Trees  []BTTreeCfg `json:"trees"`
+	Version     string            `json:"version,omitempty"`
+	ID          string            `json:"id"`
+	Select      string            `json:"selectedTree"`
+	Scope       string            `json:"scope"`
+	Trees       []BTTreeCfg       `json:"trees"`
+	CustomNodes []BTCustomNodeCfg `json:"custom_nodes,omitempty"`
+}
+
+//编辑器custom_nodes的版本
+const CustomNodeVersion = "0.3.0"
+
+//自定义节点json类型,编辑器里的节点模板
+type BTCustomNodeCfg struct {
+	Version     string                 `json:"version"`
+	Scope       string                 `json:"scope"`
+	Name        string                 `json:"name"`
+	Category    string                 `json:"category"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Properties  map[string]interface{} `json:"properties"`
 }
 
 //加载
 func LoadProjectCfg(path string) (*BTProjectCfg, bool) {
+	project, _, err := LoadProjectCfgReport(path)
+	if err != nil {
+		fmt.Println("LoadProjectCfg fail:", err)
+		return nil, false
+	}
+	return project, true
+}
 
+//加载并升级到最新版本,返回执行的迁移
+func LoadProjectCfgReport(path string) (*BTProjectCfg, []AppliedMigration, error) {
 	var project BTProjectCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
-		fmt.Println("LoadProjectCfg fail:", err)
-		return nil, false
+		return nil, nil, err
 	}
 	err = json.Unmarshal(file, &project)
 	if err != nil {
-		fmt.Println("LoadProjectCfg fail, ummarshal:", err, len(file))
-		return nil, false
+		return nil, nil, fmt.Errorf("ummarshal %s: %v", path, err)
+	}
+	applied, err := MigrateProject(&project)
+	if err != nil {
+		return nil, applied, err
 	}
-	return &project, true
+	return &project, applied, nil
 }
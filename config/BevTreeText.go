@@ -0,0 +1,791 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Text format for behavior trees.
+ *
+ * An indentation based format that can be written by hand and reviewed in
+ * a diff, as an alternative to the JSON exported by the editor:
+ *
+ *     # comments start with '#'
+ *     tree "A behavior tree" @id=main
+ *       MemSequence
+ *         Repeater "Repeat <maxLoop>x" maxLoop=2
+ *           Log info="log...11"
+ *         subtree child "child"
+ *         Log info=done
+ *
+ * Each line is one node: the node name, an optional quoted title, then
+ * properties as `key=value`. Attributes of the node itself are written as
+ * `@id=`, `@desc=` and `@category=`. A node without `@id` gets the id `nN`
+ * from its position in the tree, or `nN_2`, `nN_3`... when another node
+ * uses `nN` as its `@id`. A tree without `@id` uses its title, tree IDs
+ * must be unique. A tree without `@version=` has the latest format version.
+ *
+ * Nodes that are not connected to the root, like the ones the editor keeps
+ * around, follow the root at the same indentation as `detached` nodes:
+ *
+ *     tree "A behavior tree"
+ *       Sequence
+ *         Log info=done
+ *       detached Log info=unused
+ *
+ * Values are numbers, `true`, `false`, `null`, quoted strings, bare words or
+ * JSON objects and arrays.
+ *
+ * The category of a node is inferred from its name for the built-in nodes,
+ * otherwise from its children: none is an action, one is a decorator, more
+ * is a composite. Anything else needs an explicit `@category`.
+**/
+
+//文本格式的解析错误
+type TextError struct {
+	Line int
+	Msg  string
+}
+
+func (e *TextError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+//内置节点的类别,用于推断category
+var builtinCategories = map[string]string{
+	"Sequence":               "composite",
+	"Priority":               "composite",
+	"MemSequence":            "composite",
+	"MemPriority":            "composite",
+	"Parallel":               "composite",
+	"Subscription":           "composite",
+	"RandomSequence":         "composite",
+	"RandomSelector":         "composite",
+	"WeightedRandomSelector": "composite",
+	"UtilitySelector":        "composite",
+	"ReactiveSequence":       "composite",
+	"ReactiveFallback":       "composite",
+	"Switch":                 "composite",
+	"IfThenElse":             "composite",
+	"WhileDoElse":            "composite",
+	"ConfigurableSequence":   "composite",
+	"Race":                   "composite",
+	"Inverter":               "decorator",
+	"Limiter":                "decorator",
+	"MaxTime":                "decorator",
+	"Repeater":               "decorator",
+	"RepeatUntilFailure":     "decorator",
+	"RepeatUntilSuccess":     "decorator",
+	"Error":                  "action",
+	"Failer":                 "action",
+	"Runner":                 "action",
+	"Succeeder":              "action",
+	"Wait":                   "action",
+	"Log":                    "action",
+	"Subscriber":             "action",
+}
+
+func inferTextCategory(name string, childCount int) string {
+	if category, ok := builtinCategories[name]; ok {
+		return category
+	}
+	switch childCount {
+	case 0:
+		return "action"
+	case 1:
+		return "decorator"
+	}
+	return "composite"
+}
+
+type textNode struct {
+	line     int
+	indent   string
+	cfg      BTNodeCfg
+	children []*textNode
+}
+
+type textTree struct {
+	line int
+	cfg  BTTreeCfg
+	root *textNode
+	//没有连接到根节点的节点
+	detached []*textNode
+	count    int
+	//用@id指定的ID
+	explicit map[string]bool
+}
+
+//解析只包含一棵树的文本
+func ParseTreeText(src string) (*BTTreeCfg, error) {
+	trees, err := ParseTreesText(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(trees) != 1 {
+		return nil, &TextError{1, fmt.Sprintf("expected one tree, found %d", len(trees))}
+	}
+	return &trees[0], nil
+}
+
+//解析文本中的所有树
+func ParseTreesText(src string) ([]BTTreeCfg, error) {
+	var trees []*textTree
+	var tree *textTree
+	var stack []*textNode
+
+	for i, raw := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		tokens, err := tokenizeTextLine(strings.TrimRight(raw, " \t\r"))
+		if err != nil {
+			return nil, &TextError{lineNo, err.Error()}
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		indent := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+
+		if indent == "" {
+			if tokens[0].text != "tree" || tokens[0].quoted {
+				return nil, &TextError{lineNo, fmt.Sprintf("expected tree header, found %q", tokens[0].text)}
+			}
+			tree = &textTree{line: lineNo}
+			if err := parseTextTreeHeader(tree, tokens[1:]); err != nil {
+				return nil, &TextError{lineNo, err.Error()}
+			}
+			trees = append(trees, tree)
+			stack = stack[:0]
+			continue
+		}
+		if tree == nil {
+			return nil, &TextError{lineNo, "node outside of a tree"}
+		}
+
+		//detached关键字后是没有连接到根节点的节点
+		detached := tokens[0].text == "detached" && !tokens[0].quoted && !tokens[0].hasValue && len(tokens) > 1 && !tokens[1].hasValue
+		if detached {
+			tokens = tokens[1:]
+		}
+		node := &textNode{line: lineNo}
+		if err := parseTextNode(node, tokens); err != nil {
+			return nil, &TextError{lineNo, err.Error()}
+		}
+
+		node.indent = indent
+		for len(stack) > 0 && !isDeeperIndent(indent, stack[len(stack)-1].indent) {
+			stack = stack[:len(stack)-1]
+		}
+		switch {
+		case len(stack) == 0 && tree.root != nil && indent != tree.root.indent:
+			return nil, &TextError{lineNo, "inconsistent indentation"}
+		case len(stack) == 0 && detached:
+			if tree.root == nil {
+				return nil, &TextError{lineNo, "detached node before the root node"}
+			}
+			tree.detached = append(tree.detached, node)
+		case len(stack) == 0:
+			if tree.root != nil {
+				return nil, &TextError{lineNo, "tree has more than one root node, mark the others as detached"}
+			}
+			tree.root = node
+		case detached:
+			return nil, &TextError{lineNo, "only nodes of the tree level can be detached"}
+		default:
+			parent := stack[len(stack)-1]
+			if n := len(parent.children); n > 0 && parent.children[n-1].indent != indent {
+				return nil, &TextError{lineNo, "inconsistent indentation"}
+			}
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	result := make([]BTTreeCfg, 0, len(trees))
+	treeIDs := make(map[string]int)
+	for n, tree := range trees {
+		if tree.root == nil {
+			return nil, &TextError{tree.line, "tree has no root node"}
+		}
+		if tree.cfg.ID == "" {
+			tree.cfg.ID = tree.cfg.Title
+			if tree.cfg.ID == "" {
+				tree.cfg.ID = fmt.Sprintf("tree%d", n+1)
+			}
+		}
+		if line, ok := treeIDs[tree.cfg.ID]; ok {
+			return nil, &TextError{tree.line, fmt.Sprintf("duplicate tree id %s, first used on line %d", tree.cfg.ID, line)}
+		}
+		treeIDs[tree.cfg.ID] = tree.line
+		tree.cfg.Nodes = make(map[string]BTNodeCfg)
+		tree.explicit = make(map[string]bool)
+		roots := append([]*textNode{tree.root}, tree.detached...)
+		for _, root := range roots {
+			collectTextIDs(tree, root)
+		}
+		for _, root := range roots {
+			if _, err := buildTextNode(tree, root); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, tree.cfg)
+	}
+	return result, nil
+}
+
+//缩进是否比父节点更深
+func isDeeperIndent(indent, parent string) bool {
+	return len(indent) > len(parent) && strings.HasPrefix(indent, parent)
+}
+
+//显式的ID,自动生成的ID要跳过它们
+func collectTextIDs(tree *textTree, node *textNode) {
+	if node.cfg.Id != "" {
+		tree.explicit[node.cfg.Id] = true
+	}
+	for _, child := range node.children {
+		collectTextIDs(tree, child)
+	}
+}
+
+func buildTextNode(tree *textTree, node *textNode) (string, error) {
+	tree.count++
+	cfg := node.cfg
+	if cfg.Id == "" {
+		cfg.Id = autoTextID(tree.count, tree.explicit)
+	}
+	if _, ok := tree.cfg.Nodes[cfg.Id]; ok {
+		return "", &TextError{node.line, "duplicate node id " + cfg.Id}
+	}
+	if tree.cfg.Root == "" {
+		tree.cfg.Root = cfg.Id
+	}
+	// reserve the id before visiting the children
+	tree.cfg.Nodes[cfg.Id] = cfg
+
+	if cfg.Category == "" {
+		cfg.Category = inferTextCategory(cfg.Name, len(node.children))
+	}
+	switch cfg.Category {
+	case "composite":
+		cfg.Children = make([]string, 0, len(node.children))
+	case "decorator":
+		if len(node.children) > 1 {
+			return "", &TextError{node.line, "decorator " + cfg.Name + " can only have one child"}
+		}
+	default:
+		if len(node.children) > 0 {
+			return "", &TextError{node.line, cfg.Category + " " + cfg.Name + " cannot have children"}
+		}
+	}
+	for _, child := range node.children {
+		id, err := buildTextNode(tree, child)
+		if err != nil {
+			return "", err
+		}
+		if cfg.Category == "decorator" {
+			cfg.Child = id
+		} else {
+			cfg.Children = append(cfg.Children, id)
+		}
+	}
+	tree.cfg.Nodes[cfg.Id] = cfg
+	return cfg.Id, nil
+}
+
+//按位置生成的节点ID,跳过用@id指定的ID
+func autoTextID(position int, explicit map[string]bool) string {
+	id := fmt.Sprintf("n%d", position)
+	for i := 2; explicit[id]; i++ {
+		id = fmt.Sprintf("n%d_%d", position, i)
+	}
+	return id
+}
+
+func parseTextTreeHeader(tree *textTree, tokens []textToken) error {
+	tree.cfg.Version = LatestVersion
+	tree.cfg.Properties = make(map[string]interface{})
+	if len(tokens) > 0 && !tokens[0].hasValue {
+		tree.cfg.Title = tokens[0].text
+		tokens = tokens[1:]
+	}
+	for _, tok := range tokens {
+		if !tok.hasValue {
+			return fmt.Errorf("unexpected %q", tok.text)
+		}
+		switch tok.text {
+		case "@id":
+			tree.cfg.ID = valueString(tok.value)
+		case "@desc":
+			tree.cfg.Description = valueString(tok.value)
+		case "@version":
+			tree.cfg.Version = valueString(tok.value)
+		default:
+			if strings.HasPrefix(tok.text, "@") {
+				return fmt.Errorf("unknown tree attribute %s", tok.text)
+			}
+			tree.cfg.Properties[tok.text] = tok.value
+		}
+	}
+	return nil
+}
+
+func parseTextNode(node *textNode, tokens []textToken) error {
+	cfg := &node.cfg
+	cfg.Properties = make(map[string]interface{})
+	if tokens[0].hasValue {
+		return fmt.Errorf("expected node name, found %s=", tokens[0].text)
+	}
+	cfg.Name = tokens[0].text
+	keyword := !tokens[0].quoted
+	tokens = tokens[1:]
+	//subtree关键字后是子树名,可以带引号;带引号的"subtree"是普通节点名
+	if keyword && cfg.Name == "subtree" && len(tokens) > 0 && !tokens[0].hasValue {
+		cfg.Category = "tree"
+		cfg.Name = tokens[0].text
+		tokens = tokens[1:]
+	}
+	cfg.Title = cfg.Name
+	if len(tokens) > 0 && tokens[0].quoted && !tokens[0].hasValue {
+		cfg.Title = tokens[0].text
+		tokens = tokens[1:]
+	}
+	for _, tok := range tokens {
+		if !tok.hasValue {
+			return fmt.Errorf("unexpected %q, properties are written as key=value", tok.text)
+		}
+		switch tok.text {
+		case "@id":
+			cfg.Id = valueString(tok.value)
+		case "@desc":
+			cfg.Description = valueString(tok.value)
+		case "@category":
+			cfg.Category = valueString(tok.value)
+		default:
+			if strings.HasPrefix(tok.text, "@") {
+				return fmt.Errorf("unknown node attribute %s", tok.text)
+			}
+			if _, ok := cfg.Properties[tok.text]; ok {
+				return fmt.Errorf("duplicate property %s", tok.text)
+			}
+			cfg.Properties[tok.text] = tok.value
+		}
+	}
+	return nil
+}
+
+func valueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return formatTextValue(v)
+}
+
+//------------------------tokenizer-------------------------
+
+type textToken struct {
+	text     string
+	quoted   bool
+	hasValue bool
+	value    interface{}
+}
+
+var textNumberRe = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+var textBareRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-/:<>]*$`)
+
+func tokenizeTextLine(line string) ([]textToken, error) {
+	var tokens []textToken
+	i := 0
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i >= len(line) || line[i] == '#' {
+			return tokens, nil
+		}
+		var tok textToken
+		if line[i] == '"' {
+			s, n, err := scanTextQuoted(line[i:])
+			if err != nil {
+				return nil, err
+			}
+			tok.text, tok.quoted = s, true
+			i += n
+		} else {
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != '=' {
+				i++
+			}
+			tok.text = line[start:i]
+		}
+		if i < len(line) && line[i] == '=' {
+			if tok.text == "" {
+				return nil, fmt.Errorf("missing property name before '='")
+			}
+			i++
+			v, n, err := scanTextValue(line[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", tok.text, err)
+			}
+			tok.hasValue, tok.value = true, v
+			i += n
+		}
+		if i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			return nil, fmt.Errorf("unexpected character %q", line[i])
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func scanTextQuoted(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return v, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func scanTextValue(s string) (interface{}, int, error) {
+	if s == "" || s[0] == ' ' || s[0] == '\t' {
+		return nil, 0, fmt.Errorf("missing value")
+	}
+	switch s[0] {
+	case '"':
+		v, n, err := scanTextQuoted(s)
+		return v, n, err
+	case '{', '[':
+		n, err := scanTextJSON(s)
+		if err != nil {
+			return nil, 0, err
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(s[:n]), &v); err != nil {
+			return nil, 0, fmt.Errorf("invalid JSON value: %v", err)
+		}
+		return v, n, nil
+	}
+	n := strings.IndexAny(s, " \t")
+	if n < 0 {
+		n = len(s)
+	}
+	word := s[:n]
+	switch {
+	case word == "true":
+		return true, n, nil
+	case word == "false":
+		return false, n, nil
+	case word == "null":
+		return nil, n, nil
+	case textNumberRe.MatchString(word):
+		f, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		return f, n, nil
+	}
+	return word, n, nil
+}
+
+//找到匹配的JSON结束括号
+func scanTextJSON(s string) (int, error) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			_, n, err := scanTextQuoted(s[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n - 1
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated JSON value")
+}
+
+//------------------------printer-------------------------
+
+//把树输出为文本格式,被多个父节点引用的节点、找不到的子节点和环不能表示,返回错误
+func FormatTreeText(tree *BTTreeCfg) (string, error) {
+	var buf bytes.Buffer
+	if err := writeTextTree(&buf, tree, 1); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+//把多棵树输出为文本格式,树之间空一行
+func FormatTreesText(trees []BTTreeCfg) (string, error) {
+	var buf bytes.Buffer
+	for i := range trees {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if err := writeTextTree(&buf, &trees[i], i+1); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func writeTextTree(buf *bytes.Buffer, tree *BTTreeCfg, index int) error {
+	buf.WriteString("tree")
+	if tree.Title != "" {
+		buf.WriteString(" " + strconv.Quote(tree.Title))
+	}
+	autoID := tree.Title
+	if autoID == "" {
+		autoID = fmt.Sprintf("tree%d", index)
+	}
+	if tree.ID != autoID {
+		buf.WriteString(" @id=" + formatTextValue(tree.ID))
+	}
+	if tree.Version != "" && tree.Version != LatestVersion {
+		buf.WriteString(" @version=" + formatTextValue(tree.Version))
+	}
+	if tree.Description != "" {
+		buf.WriteString(" @desc=" + formatTextValue(tree.Description))
+	}
+	writeTextProperties(buf, tree.Properties)
+	buf.WriteString("\n")
+
+	order, detached, err := textNodeOrder(tree)
+	if err != nil {
+		return err
+	}
+	//和解析时一样,生成的ID跳过其他节点的ID
+	position := make(map[string]int, len(order))
+	for i, id := range order {
+		position[id] = i + 1
+	}
+	explicit := make(map[string]bool, len(order))
+	for i, id := range order {
+		if !isAutoTextID(id, i+1) {
+			explicit[id] = true
+		}
+	}
+
+	isDetached := make(map[string]bool, len(detached))
+	for _, id := range detached {
+		isDetached[id] = true
+	}
+	var walk func(id string, depth int)
+	walk = func(id string, depth int) {
+		node := tree.Nodes[id]
+		children, category := textChildren(&node)
+
+		buf.WriteString(strings.Repeat("  ", depth))
+		if isDetached[id] {
+			buf.WriteString("detached ")
+		}
+		if category == "tree" {
+			buf.WriteString("subtree " + formatTextName(node.Name))
+		} else if node.Name == "subtree" || node.Name == "detached" {
+			buf.WriteString(strconv.Quote(node.Name))
+		} else {
+			buf.WriteString(formatTextName(node.Name))
+		}
+		if node.Title != node.Name {
+			buf.WriteString(" " + strconv.Quote(node.Title))
+		}
+		if id != autoTextID(position[id], explicit) {
+			buf.WriteString(" @id=" + formatTextValue(id))
+		}
+		if category != "" && category != "tree" && category != inferTextCategory(node.Name, len(children)) {
+			buf.WriteString(" @category=" + formatTextValue(category))
+		}
+		if node.Description != "" {
+			buf.WriteString(" @desc=" + formatTextValue(node.Description))
+		}
+		writeTextProperties(buf, node.Properties)
+		buf.WriteString("\n")
+		for _, child := range children {
+			walk(child, depth+1)
+		}
+	}
+	walk(tree.Root, 1)
+	for _, id := range detached {
+		walk(id, 1)
+	}
+	return nil
+}
+
+//子节点和推断的类别
+func textChildren(node *BTNodeCfg) ([]string, string) {
+	children := node.Children
+	category := node.Category
+	if len(node.Child) > 0 {
+		children = []string{node.Child}
+		if category == "" {
+			category = "decorator"
+		}
+	}
+	if category == "" && node.Children != nil {
+		category = "composite"
+	}
+	return children, category
+}
+
+//输出节点的顺序(和解析时的位置一致)和没有连接到根节点的节点
+func textNodeOrder(tree *BTTreeCfg) ([]string, []string, error) {
+	var order []string
+	visited := make(map[string]bool, len(tree.Nodes))
+	var walk func(id string) error
+	walk = func(id string) error {
+		node, ok := tree.Nodes[id]
+		switch {
+		case !ok:
+			return fmt.Errorf("tree %s: node %q not found", tree.Title, id)
+		case visited[id]:
+			return fmt.Errorf("tree %s: node %q has more than one parent", tree.Title, id)
+		}
+		visited[id] = true
+		order = append(order, id)
+		children, _ := textChildren(&node)
+		for _, child := range children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree.Root); err != nil {
+		return nil, nil, err
+	}
+
+	//其他节点里,不是任何节点的子节点的节点是detached的根
+	referenced := make(map[string]bool)
+	var ids []string
+	for id, node := range tree.Nodes {
+		children, _ := textChildren(&node)
+		for _, child := range children {
+			referenced[child] = true
+		}
+		if !visited[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	var detached []string
+	for _, id := range ids {
+		if !referenced[id] {
+			detached = append(detached, id)
+			if err := walk(id); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(order) != len(tree.Nodes) {
+		var cycle []string
+		for _, id := range ids {
+			if !visited[id] {
+				cycle = append(cycle, id)
+			}
+		}
+		return nil, nil, fmt.Errorf("tree %s: nodes in a cycle: %s", tree.Title, strings.Join(cycle, ", "))
+	}
+	return order, detached, nil
+}
+
+//节点ID是否为按位置生成的nN或nN_M
+func isAutoTextID(id string, position int) bool {
+	prefix := fmt.Sprintf("n%d", position)
+	if id == prefix {
+		return true
+	}
+	if !strings.HasPrefix(id, prefix+"_") {
+		return false
+	}
+	n, err := strconv.Atoi(id[len(prefix)+1:])
+	return err == nil && n >= 2 && strconv.Itoa(n) == id[len(prefix)+1:]
+}
+
+func writeTextProperties(buf *bytes.Buffer, props map[string]interface{}) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(" " + formatTextName(k) + "=" + formatTextValue(props[k]))
+	}
+}
+
+func formatTextName(name string) string {
+	if name != "" && strings.IndexAny(name, " \t=\"#") < 0 {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func formatTextValue(v interface{}) string {
+	switch tv := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if textBareRe.MatchString(tv) && !textNumberRe.MatchString(tv) && tv != "true" && tv != "false" && tv != "null" {
+			return tv
+		}
+		return strconv.Quote(tv)
+	case bool:
+		return strconv.FormatBool(tv)
+	case float64:
+		return strconv.FormatFloat(tv, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(tv), 'g', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(tv)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return strconv.Quote(fmt.Sprint(v))
+	}
+	return string(data)
+}
+
+//加载文本格式的树
+func LoadTreeTextCfg(path string) (*BTTreeCfg, bool) {
+	tree, _, err := LoadTreeTextCfgReport(path)
+	if err != nil {
+		fmt.Println("LoadTreeTextCfg fail:", path, err)
+		return nil, false
+	}
+	return tree, true
+}
+
+//加载文本格式的树并按@version升级到最新版本,返回执行的迁移
+func LoadTreeTextCfgReport(path string) (*BTTreeCfg, []AppliedMigration, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := ParseTreeText(string(file))
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := MigrateTree(tree)
+	if err != nil {
+		return nil, applied, err
+	}
+	return tree, applied, nil
+}
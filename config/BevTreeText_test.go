@@ -0,0 +1,243 @@
+package config_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+func parseText(t *testing.T, src string) []config.BTTreeCfg {
+	t.Helper()
+	trees, err := config.ParseTreesText(src)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, src)
+	}
+	return trees
+}
+
+func formatText(t *testing.T, trees []config.BTTreeCfg) string {
+	t.Helper()
+	text, err := config.FormatTreesText(trees)
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	return text
+}
+
+//节点的结构和属性,忽略编辑器里空的children和默认的title
+func sameNodes(t *testing.T, want, got *config.BTTreeCfg) {
+	t.Helper()
+	if want.ID != got.ID || want.Title != got.Title || want.Root != got.Root {
+		t.Errorf("tree %q/%q root %q, want %q/%q root %q", got.ID, got.Title, got.Root, want.ID, want.Title, want.Root)
+	}
+	if len(want.Nodes) != len(got.Nodes) {
+		t.Fatalf("tree %s has %d nodes, want %d", want.Title, len(got.Nodes), len(want.Nodes))
+	}
+	for id, w := range want.Nodes {
+		g, ok := got.Nodes[id]
+		if !ok {
+			t.Errorf("node %s is missing", id)
+			continue
+		}
+		if len(w.Children) == 0 && len(g.Children) == 0 {
+			w.Children, g.Children = nil, nil
+		}
+		if w.Title == "" {
+			w.Title = w.Name
+		}
+		if len(w.Properties) == 0 && len(g.Properties) == 0 {
+			w.Properties, g.Properties = nil, nil
+		}
+		if w.Category == "" {
+			w.Category = g.Category
+		}
+		w.Parameters, g.Parameters = nil, nil
+		if !reflect.DeepEqual(w, g) {
+			t.Errorf("node %s = %+v, want %+v", id, g, w)
+		}
+	}
+}
+
+func TestTextParse(t *testing.T) {
+	tree, err := config.ParseTreeText(`
+# a comment
+tree "Patrol" @desc="walk around" speed=2
+  MemSequence
+    Repeater "Repeat <maxLoop>x" maxLoop=3
+      Log info="log...11" # trailing comment
+    subtree Guard "guard"
+    Wait @id=wait milliseconds=500 target={"x": 1, "y": [2, 3]}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.ID != "Patrol" || tree.Description != "walk around" || tree.Properties["speed"] != 2.0 {
+		t.Errorf("tree = %+v", tree)
+	}
+	if tree.Version != config.LatestVersion {
+		t.Errorf("version = %q, want %q", tree.Version, config.LatestVersion)
+	}
+	root := tree.Nodes[tree.Root]
+	if root.Name != "MemSequence" || root.Category != "composite" || !reflect.DeepEqual(root.Children, []string{"n2", "n4", "wait"}) {
+		t.Errorf("root = %+v", root)
+	}
+	if rep := tree.Nodes["n2"]; rep.Category != "decorator" || rep.Child != "n3" || rep.Title != "Repeat <maxLoop>x" || rep.Properties["maxLoop"] != 3.0 {
+		t.Errorf("repeater = %+v", rep)
+	}
+	if log := tree.Nodes["n3"]; log.Properties["info"] != "log...11" {
+		t.Errorf("log = %+v", log)
+	}
+	if sub := tree.Nodes["n4"]; sub.Category != "tree" || sub.Name != "Guard" || sub.Title != "guard" {
+		t.Errorf("subtree = %+v", sub)
+	}
+	want := map[string]interface{}{"x": 1.0, "y": []interface{}{2.0, 3.0}}
+	if wait := tree.Nodes["wait"]; wait.Properties["milliseconds"] != 500.0 || !reflect.DeepEqual(wait.Properties["target"], want) {
+		t.Errorf("wait = %+v", wait)
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	cases := []struct {
+		name, src, err string
+	}{
+		{"node outside tree", "  Log\n", "line 1: node outside of a tree"},
+		{"no header", "Log\n", `line 1: expected tree header, found "Log"`},
+		{"two roots", "tree a\n  Log\n  Log\n", "line 3: tree has more than one root node"},
+		{"root indentation", "tree a\n    Sequence\n  Log\n", "line 3: inconsistent indentation"},
+		{"sibling indentation", "tree a\n  Sequence\n      Log\n    Log\n", "line 4: inconsistent indentation"},
+		{"tabs and spaces", "tree a\n  Sequence\n  \tLog\n    Log\n", "line 4: inconsistent indentation"},
+		{"no root", "tree a\ntree b\n  Log\n", "line 1: tree has no root node"},
+		{"duplicate tree", "tree a\n  Log\ntree a\n  Log\n", "line 3: duplicate tree id a, first used on line 1"},
+		{"duplicate tree id", "tree a @id=x\n  Log\ntree b @id=x\n  Log\n", "line 3: duplicate tree id x"},
+		{"duplicate node id", "tree a\n  Sequence\n    Log @id=x\n    Log @id=x\n", "line 4: duplicate node id x"},
+		{"action with children", "tree a\n  Log @category=action\n    Log\n", "line 2: action Log cannot have children"},
+		{"decorator children", "tree a\n  Inverter\n    Log\n    Log\n", "line 2: decorator Inverter can only have one child"},
+		{"unterminated string", "tree a\n  Log info=\"x\n", "line 2: info: unterminated string"},
+		{"missing value", "tree a\n  Log info=\n", "line 2: info: missing value"},
+		{"bare word", "tree a\n  Log info\n", `line 2: unexpected "info", properties are written as key=value`},
+		{"unknown attribute", "tree a\n  Log @color=red\n", "line 2: unknown node attribute @color"},
+		{"duplicate property", "tree a\n  Log a=1 a=2\n", "line 2: duplicate property a"},
+		{"detached first", "tree a\n  detached Log\n", "line 2: detached node before the root node"},
+		{"detached child", "tree a\n  Sequence\n    detached Log\n", "line 3: only nodes of the tree level can be detached"},
+	}
+	for _, c := range cases {
+		_, err := config.ParseTreesText(c.src)
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+}
+
+func TestTextGeneratedIDs(t *testing.T) {
+	//n2 is taken by the root, the second node gets n2_2
+	src := "tree \"t\"\n  Sequence @id=n2\n    Log\n    Wait\n"
+	trees := parseText(t, src)
+	if _, ok := trees[0].Nodes["n2_2"]; !ok {
+		t.Fatalf("nodes = %v", trees[0].Nodes)
+	}
+	if text := formatText(t, trees); text != src {
+		t.Errorf("format =\n%s\nwant\n%s", text, src)
+	}
+}
+
+func TestTextQuoting(t *testing.T) {
+	src := `tree "my tree" @id=t1
+  "subtree" "not a subtree"
+    "detached" a="1" b="true" c="two words" d=null e="say \"hi\"" "f g"=1
+  detached subtree "Other Tree"
+  detached "My Node" "title #1" @desc="#not a comment"
+`
+	trees := parseText(t, src)
+	tree := &trees[0]
+	if n := tree.Nodes["n1"]; n.Name != "subtree" || n.Category != "decorator" || n.Title != "not a subtree" {
+		t.Errorf("n1 = %+v", n)
+	}
+	props := tree.Nodes["n2"].Properties
+	want := map[string]interface{}{"a": "1", "b": "true", "c": "two words", "d": nil, "e": `say "hi"`, "f g": 1.0}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("properties = %v, want %v", props, want)
+	}
+	if n := tree.Nodes["n3"]; n.Name != "Other Tree" || n.Category != "tree" {
+		t.Errorf("n3 = %+v", n)
+	}
+	if n := tree.Nodes["n4"]; n.Name != "My Node" || n.Title != "title #1" || n.Description != "#not a comment" {
+		t.Errorf("n4 = %+v", n)
+	}
+	if text := formatText(t, trees); text != src {
+		t.Errorf("format =\n%s\nwant\n%s", text, src)
+	}
+}
+
+func TestTextDetachedNodes(t *testing.T) {
+	tree := config.BTTreeCfg{
+		ID: "t", Title: "t", Root: "a",
+		Nodes: map[string]config.BTNodeCfg{
+			"a": {Id: "a", Name: "Sequence", Title: "Sequence", Category: "composite", Children: []string{"b"}},
+			"b": {Id: "b", Name: "Log", Title: "Log", Category: "action"},
+			"c": {Id: "c", Name: "Inverter", Title: "Inverter", Category: "decorator", Child: "d"},
+			"d": {Id: "d", Name: "Failer", Title: "Failer", Category: "action"},
+			"e": {Id: "e", Name: "Succeeder", Title: "Succeeder", Category: "action"},
+		},
+	}
+	text := formatText(t, []config.BTTreeCfg{tree})
+	want := `tree "t"
+  Sequence @id=a
+    Log @id=b
+  detached Inverter @id=c
+    Failer @id=d
+  detached Succeeder @id=e
+`
+	if text != want {
+		t.Fatalf("format =\n%s\nwant\n%s", text, want)
+	}
+	sameNodes(t, &tree, &parseText(t, text)[0])
+}
+
+func TestTextUnrepresentable(t *testing.T) {
+	cases := []struct {
+		name  string
+		nodes map[string]config.BTNodeCfg
+		err   string
+	}{
+		{"missing child", map[string]config.BTNodeCfg{
+			"a": {Id: "a", Name: "Inverter", Child: "x"},
+		}, `tree t: node "x" not found`},
+		{"shared child", map[string]config.BTNodeCfg{
+			"a": {Id: "a", Name: "Sequence", Children: []string{"b", "b"}},
+			"b": {Id: "b", Name: "Log"},
+		}, `tree t: node "b" has more than one parent`},
+		{"cycle", map[string]config.BTNodeCfg{
+			"a": {Id: "a", Name: "Log"},
+			"b": {Id: "b", Name: "Inverter", Child: "c"},
+			"c": {Id: "c", Name: "Inverter", Child: "b"},
+		}, "tree t: nodes in a cycle: b, c"},
+	}
+	for _, c := range cases {
+		tree := &config.BTTreeCfg{ID: "t", Title: "t", Root: "a", Nodes: c.nodes}
+		if _, err := config.FormatTreeText(tree); err == nil || err.Error() != c.err {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+}
+
+func TestTextRoundTripEditorFiles(t *testing.T) {
+	for _, path := range []string{"../examples/mmoarpg/zt.b3", "../examples/memsubtree/memsubtree.b3"} {
+		project, ok := config.LoadProjectCfg(path)
+		if !ok {
+			t.Fatalf("load %s", path)
+		}
+		text := formatText(t, project.Trees)
+		trees := parseText(t, text)
+		if len(trees) != len(project.Trees) {
+			t.Fatalf("%s: %d trees, want %d", path, len(trees), len(project.Trees))
+		}
+		for i := range trees {
+			sameNodes(t, &project.Trees[i], &trees[i])
+		}
+		if again := formatText(t, trees); again != text {
+			t.Errorf("%s: formatting the parsed text changed it", path)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/**
+ * Flattening replaces every subtree node of a tree by a copy of the
+ * referenced tree, so that the loaded tree does not look subtrees up while
+ * ticking and every use of a subtree has its own node memory.
+ *
+ * The nodes of the parent tree keep their IDs. An inlined node gets the ID
+ * of the subtree node followed by its original ID, for example
+ * `<subtree node id>/<node id>`, nested subtrees add one more prefix. The
+ * returned SourceMap leads every node back to the tree and node it was
+ * copied from.
+ *
+ * @class Flatten
+**/
+
+//内联后节点的来源
+type NodeSource struct {
+	//节点原来所在的树的ID
+	Tree string
+	//节点在原来的树里的ID
+	NodeID string
+	//经过的子树节点ID,从外到内,不在子树里时为空
+	SubtreeNodes []string
+}
+
+//按内联后的节点ID索引的来源
+type SourceMap map[string]NodeSource
+
+//按ID查找,找不到再按标题查找
+func (p *BTProjectCfg) FindTree(key string) *BTTreeCfg {
+	for i := range p.Trees {
+		if p.Trees[i].ID == key {
+			return &p.Trees[i]
+		}
+	}
+	for i := range p.Trees {
+		if p.Trees[i].Title == key {
+			return &p.Trees[i]
+		}
+	}
+	return nil
+}
+
+//把子树内联到树里,resolve按子树节点的名字或标题查找树
+func FlattenTree(tree *BTTreeCfg, resolve func(name string) *BTTreeCfg) (*BTTreeCfg, SourceMap, error) {
+	out := *tree
+	out.Nodes = make(map[string]BTNodeCfg, len(tree.Nodes))
+	f := &flattener{resolve: resolve, out: &out, sources: make(SourceMap)}
+	root, err := f.copyTree(tree, "", nil, []*BTTreeCfg{tree})
+	if err != nil {
+		return nil, nil, err
+	}
+	out.Root = root
+	return &out, f.sources, nil
+}
+
+type flattener struct {
+	resolve func(name string) *BTTreeCfg
+	out     *BTTreeCfg
+	sources SourceMap
+}
+
+//复制树的节点,返回根节点的新ID
+func (f *flattener) copyTree(tree *BTTreeCfg, prefix string, subtreeNodes []string, stack []*BTTreeCfg) (string, error) {
+	ids := make([]string, 0, len(tree.Nodes))
+	for id := range tree.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// subtree nodes are replaced by the root of the inlined tree
+	final := make(map[string]string, len(ids))
+	for _, id := range ids {
+		node := tree.Nodes[id]
+		if node.Category != "tree" {
+			final[id] = prefix + id
+			continue
+		}
+		sub, err := f.findSubtree(&node)
+		if err != nil {
+			return "", fmt.Errorf("tree %s node %s: %v", tree.Title, id, err)
+		}
+		//按ID比较,resolve可能每次返回树的副本
+		for _, t := range stack {
+			if flattenKey(t) == flattenKey(sub) {
+				titles := make([]string, 0, len(stack)+1)
+				for _, s := range stack {
+					titles = append(titles, s.Title)
+				}
+				return "", fmt.Errorf("subtree recursion: %s > %s", strings.Join(titles, " > "), sub.Title)
+			}
+		}
+		nested := append(append([]string{}, subtreeNodes...), id)
+		root, err := f.copyTree(sub, prefix+id+"/", nested, append(stack, sub))
+		if err != nil {
+			return "", err
+		}
+		final[id] = root
+	}
+	mapID := func(id string) string {
+		if m, ok := final[id]; ok {
+			return m
+		}
+		return prefix + id
+	}
+
+	for _, id := range ids {
+		node := tree.Nodes[id]
+		if node.Category == "tree" {
+			continue
+		}
+		node.Id = final[id]
+		if node.Child != "" {
+			node.Child = mapID(node.Child)
+		}
+		if node.Children != nil {
+			children := make([]string, len(node.Children))
+			for i, c := range node.Children {
+				children[i] = mapID(c)
+			}
+			node.Children = children
+		}
+		if node.Properties != nil {
+			props := make(map[string]interface{}, len(node.Properties))
+			for k, v := range node.Properties {
+				props[k] = v
+			}
+			node.Properties = props
+		}
+		if _, ok := f.out.Nodes[node.Id]; ok {
+			return "", fmt.Errorf("tree %s node %s: inlined id %s is already used", tree.Title, id, node.Id)
+		}
+		f.out.Nodes[node.Id] = node
+		f.sources[node.Id] = NodeSource{Tree: tree.ID, NodeID: id, SubtreeNodes: subtreeNodes}
+	}
+	return mapID(tree.Root), nil
+}
+
+//检查递归用的树标识,没有ID时用标题
+func flattenKey(tree *BTTreeCfg) string {
+	if tree.ID != "" {
+		return tree.ID
+	}
+	return tree.Title
+}
+
+func (f *flattener) findSubtree(node *BTNodeCfg) (*BTTreeCfg, error) {
+	if f.resolve == nil {
+		return nil, fmt.Errorf("no resolver for subtree %s", node.Name)
+	}
+	if sub := f.resolve(node.Name); sub != nil {
+		return sub, nil
+	}
+	if sub := f.resolve(node.Title); sub != nil {
+		return sub, nil
+	}
+	return nil, fmt.Errorf("subtree %s(%s) not found", node.Name, node.Title)
+}
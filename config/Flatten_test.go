@@ -0,0 +1,148 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+func flattenProject(t *testing.T, src string) *config.BTProjectCfg {
+	t.Helper()
+	return &config.BTProjectCfg{Trees: parseText(t, src)}
+}
+
+const nestedSubtrees = `tree "main" @id=main
+  Sequence @id=root
+    subtree Guard @id=g1
+    subtree Guard @id=g2
+tree "Guard" @id=guard
+  Priority @id=p
+    subtree Attack @id=atk
+    Wait @id=w milliseconds=100
+tree "Attack" @id=attack
+  Log @id=log info=attack
+`
+
+func TestFlattenTree(t *testing.T) {
+	project := flattenProject(t, nestedSubtrees)
+	flat, sources, err := config.FlattenTree(project.FindTree("main"), project.FindTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flat.Root != "root" || flat.ID != "main" {
+		t.Errorf("flat tree %s root %s", flat.ID, flat.Root)
+	}
+	var ids []string
+	for id := range flat.Nodes {
+		ids = append(ids, id)
+	}
+	if len(ids) != 7 {
+		t.Errorf("nodes = %v", ids)
+	}
+	if c := flat.Nodes["root"].Children; !reflect.DeepEqual(c, []string{"g1/p", "g2/p"}) {
+		t.Errorf("root children = %v", c)
+	}
+	if c := flat.Nodes["g2/p"].Children; !reflect.DeepEqual(c, []string{"g2/atk/log", "g2/w"}) {
+		t.Errorf("g2/p children = %v", c)
+	}
+	if n := flat.Nodes["g1/atk/log"]; n.Id != "g1/atk/log" || n.Properties["info"] != "attack" {
+		t.Errorf("log = %+v", n)
+	}
+	want := map[string]config.NodeSource{
+		"root":       {Tree: "main", NodeID: "root"},
+		"g1/p":       {Tree: "guard", NodeID: "p", SubtreeNodes: []string{"g1"}},
+		"g2/w":       {Tree: "guard", NodeID: "w", SubtreeNodes: []string{"g2"}},
+		"g1/atk/log": {Tree: "attack", NodeID: "log", SubtreeNodes: []string{"g1", "atk"}},
+	}
+	for id, w := range want {
+		if got := sources[id]; !reflect.DeepEqual(got, w) {
+			t.Errorf("source of %s = %+v, want %+v", id, got, w)
+		}
+	}
+	if len(sources) != len(flat.Nodes) {
+		t.Errorf("%d sources for %d nodes", len(sources), len(flat.Nodes))
+	}
+}
+
+func TestFlattenCopiesNodes(t *testing.T) {
+	project := flattenProject(t, nestedSubtrees)
+	flat, _, err := config.FlattenTree(project.FindTree("main"), project.FindTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//each use of a subtree has its own properties, the source trees stay unchanged
+	flat.Nodes["g1/w"].Properties["milliseconds"] = 1.0
+	if ms := flat.Nodes["g2/w"].Properties["milliseconds"]; ms != 100.0 {
+		t.Errorf("g2/w milliseconds = %v", ms)
+	}
+	if ms := project.FindTree("guard").Nodes["w"].Properties["milliseconds"]; ms != 100.0 {
+		t.Errorf("source milliseconds = %v", ms)
+	}
+	if n := project.FindTree("main").Nodes["g1"]; n.Category != "tree" {
+		t.Errorf("source subtree node = %+v", n)
+	}
+}
+
+func TestFlattenSubtreeRoot(t *testing.T) {
+	//a tree whose root is a subtree node gets the root of the inlined tree
+	project := flattenProject(t, "tree \"main\" @id=main\n  subtree Attack @id=s\ntree \"Attack\" @id=attack\n  Log @id=log\n")
+	flat, _, err := config.FlattenTree(project.FindTree("main"), project.FindTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flat.Root != "s/log" || len(flat.Nodes) != 1 {
+		t.Errorf("root %s, nodes %v", flat.Root, flat.Nodes)
+	}
+}
+
+func TestFlattenErrors(t *testing.T) {
+	cases := []struct {
+		name, src, err string
+	}{
+		{"recursion", "tree \"a\" @id=a\n  subtree b @id=x\ntree \"b\" @id=b\n  Sequence @id=s\n    subtree a @id=y\n",
+			"subtree recursion: a > b > a"},
+		{"self", "tree \"a\" @id=a\n  subtree a @id=x\n", "subtree recursion: a > a"},
+		{"id collision", "tree \"a\" @id=a\n  Sequence @id=s\n    subtree b @id=x\n    Log @id=x/log\ntree \"b\" @id=b\n  Log @id=log\n",
+			"tree a node x/log: inlined id x/log is already used"},
+		{"missing", "tree \"a\" @id=a\n  Sequence @id=s\n    subtree Gone \"gone\" @id=x\n",
+			"tree a node x: subtree Gone(gone) not found"},
+	}
+	for _, c := range cases {
+		project := flattenProject(t, c.src)
+		_, _, err := config.FlattenTree(&project.Trees[0], project.FindTree)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+	//返回副本的resolve也能发现递归
+	project := flattenProject(t, "tree \"a\" @id=a\n  subtree b @id=x\ntree \"b\" @id=b\n  subtree a @id=y\n")
+	copies := func(name string) *config.BTTreeCfg {
+		if tree := project.FindTree(name); tree != nil {
+			copied := *tree
+			return &copied
+		}
+		return nil
+	}
+	if _, _, err := config.FlattenTree(&project.Trees[0], copies); err == nil || err.Error() != "subtree recursion: a > b > a" {
+		t.Errorf("copies: err = %v", err)
+	}
+	project = flattenProject(t, "tree \"a\" @id=a\n  subtree b @id=x\n")
+	if _, _, err := config.FlattenTree(&project.Trees[0], nil); err == nil || err.Error() != "tree a node x: no resolver for subtree b" {
+		t.Errorf("nil resolver: err = %v", err)
+	}
+}
+
+func TestFindTree(t *testing.T) {
+	project := flattenProject(t, "tree \"x\" @id=a\n  Log\ntree \"a\" @id=b\n  Log\n")
+	//IDs win over titles
+	if tree := project.FindTree("a"); tree == nil || tree.ID != "a" {
+		t.Errorf("FindTree(a) = %+v", tree)
+	}
+	if tree := project.FindTree("x"); tree == nil || tree.ID != "a" {
+		t.Errorf("FindTree(x) = %+v", tree)
+	}
+	if tree := project.FindTree("none"); tree != nil {
+		t.Errorf("FindTree(none) = %+v", tree)
+	}
+}
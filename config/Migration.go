@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+/**
+ * Migrations upgrade tree documents written by older editor versions. Each
+ * migration moves a tree from one version to the next, and `MigrateTree`
+ * applies them one after another until no migration starts at the version
+ * of the tree:
+ *
+ *     config.RegisterMigration(config.RenameNodesMigration(
+ *       "rename-wait", config.LatestVersion, "0.4.1",
+ *       map[string]string{"Delay": "Wait"}))
+ *
+ * A document without a version is treated as `0.1.0`.
+ *
+ * @class Migration
+**/
+
+//当前编辑器导出的格式版本,和b3.VERSION一致,添加迁移时一起升级
+const LatestVersion = b3.VERSION
+
+//没有版本号的文档
+const UnversionedVersion = "0.1.0"
+
+type Migration struct {
+	Name string
+	From string
+	To   string
+	Tree func(tree *BTTreeCfg) error
+}
+
+//已执行的迁移
+type AppliedMigration struct {
+	Name string
+	From string
+	To   string
+	Tree string
+}
+
+func (a AppliedMigration) String() string {
+	return fmt.Sprintf("%s %s->%s (tree %s)", a.Name, a.From, a.To, a.Tree)
+}
+
+//按起始版本索引的迁移
+var migrations = make(map[string]Migration)
+
+//注册迁移,同一个起始版本只能有一个迁移
+func RegisterMigration(m Migration) {
+	if m.From == m.To {
+		panic("RegisterMigration: migration " + m.Name + " does not change the version")
+	}
+	if old, ok := migrations[m.From]; ok {
+		panic("RegisterMigration: " + m.Name + " conflicts with " + old.Name + " from version " + m.From)
+	}
+	migrations[m.From] = m
+}
+
+//对每个节点执行的迁移
+func NodeMigration(name, from, to string, fn func(node *BTNodeCfg) error) Migration {
+	return Migration{Name: name, From: from, To: to, Tree: func(tree *BTTreeCfg) error {
+		for id, node := range tree.Nodes {
+			if err := fn(&node); err != nil {
+				return fmt.Errorf("node %s: %v", id, err)
+			}
+			tree.Nodes[id] = node
+		}
+		return nil
+	}}
+}
+
+//修改节点类型名的迁移
+func RenameNodesMigration(name, from, to string, names map[string]string) Migration {
+	return NodeMigration(name, from, to, func(node *BTNodeCfg) error {
+		if newName, ok := names[node.Name]; ok {
+			if node.Title == "" || node.Title == node.Name {
+				node.Title = newName
+			}
+			node.Name = newName
+		}
+		return nil
+	})
+}
+
+//升级树到最新版本
+func MigrateTree(tree *BTTreeCfg) ([]AppliedMigration, error) {
+	var applied []AppliedMigration
+	chain, err := migrationChain(tree.Version)
+	if err != nil {
+		return nil, fmt.Errorf("tree %s: %v", tree.Title, err)
+	}
+	for _, m := range chain {
+		if err := m.Tree(tree); err != nil {
+			return applied, fmt.Errorf("migration %s of tree %s: %v", m.Name, tree.Title, err)
+		}
+		applied = append(applied, AppliedMigration{m.Name, m.From, m.To, tree.Title})
+		tree.Version = m.To
+	}
+	if tree.Version == "" {
+		tree.Version = UnversionedVersion
+	}
+	return applied, nil
+}
+
+//从某个版本开始要执行的迁移
+func migrationChain(version string) ([]Migration, error) {
+	if version == "" {
+		version = UnversionedVersion
+	}
+	var chain []Migration
+	visited := make(map[string]bool)
+	for {
+		m, ok := migrations[version]
+		if !ok {
+			return chain, nil
+		}
+		if visited[version] {
+			return nil, fmt.Errorf("migration cycle at version %s", version)
+		}
+		visited[version] = true
+		chain = append(chain, m)
+		version = m.To
+	}
+}
+
+//升级工程里的所有树,没有版本号的树使用工程的版本号
+func MigrateProject(project *BTProjectCfg) ([]AppliedMigration, error) {
+	var applied []AppliedMigration
+	for i := range project.Trees {
+		tree := &project.Trees[i]
+		if tree.Version == "" {
+			tree.Version = project.Version
+		}
+		steps, err := MigrateTree(tree)
+		applied = append(applied, steps...)
+		if err != nil {
+			return applied, err
+		}
+	}
+	chain, err := migrationChain(project.Version)
+	if err != nil {
+		return applied, err
+	}
+	if len(chain) > 0 {
+		project.Version = chain[len(chain)-1].To
+	}
+	return applied, nil
+}
+
+func init() {
+	// 0.2.0 deprecated parameters in favor of properties
+	RegisterMigration(NodeMigration("parameters-to-properties", UnversionedVersion, "0.2.0", func(node *BTNodeCfg) error {
+		if len(node.Parameters) == 0 {
+			return nil
+		}
+		if node.Properties == nil {
+			node.Properties = make(map[string]interface{})
+		}
+		for k, v := range node.Parameters {
+			if _, ok := node.Properties[k]; !ok {
+				node.Properties[k] = v
+			}
+		}
+		node.Parameters = nil
+		return nil
+	}))
+	// 0.3.0 exports the category of every node
+	RegisterMigration(NodeMigration("node-categories", "0.2.0", "0.3.0", func(node *BTNodeCfg) error {
+		if node.Category != "" {
+			return nil
+		}
+		if category, ok := builtinCategories[node.Name]; ok {
+			node.Category = category
+		} else if node.Child != "" {
+			node.Category = "decorator"
+		} else if node.Children != nil {
+			node.Category = "composite"
+		}
+		return nil
+	}))
+	// 0.4.0 changed the defaults of Parallel, older documents keep the old behavior
+	RegisterMigration(NodeMigration("parallel-policies", "0.3.0", LatestVersion, func(node *BTNodeCfg) error {
+		if node.Name != "Parallel" {
+			return nil
+		}
+		if node.Properties == nil {
+			node.Properties = make(map[string]interface{})
+		}
+		_, success := node.Properties["successThreshold"]
+		_, failure := node.Properties["failureThreshold"]
+		if !success && !failure {
+			// the old Parallel succeeded when the first child finished
+			node.Properties["finishThreshold"] = 1.0
+			node.Properties["cancelRemaining"] = true
+			return nil
+		}
+		// policies set before 0.4.0 keep the defaults of that version
+		if !failure {
+			node.Properties["failureThreshold"] = 0.0
+		}
+		if _, ok := node.Properties["cancelRemaining"]; !ok {
+			node.Properties["cancelRemaining"] = false
+		}
+		return nil
+	}))
+}
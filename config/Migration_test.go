@@ -0,0 +1,217 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+//迁移名字的列表
+func migrationNames(applied []config.AppliedMigration) []string {
+	var names []string
+	for _, a := range applied {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func TestMigrateUnversionedTree(t *testing.T) {
+	tree := &config.BTTreeCfg{Title: "old", Nodes: map[string]config.BTNodeCfg{
+		"a": {Id: "a", Name: "Sequence", Children: []string{"b", "c"}},
+		"b": {Id: "b", Name: "Wait", Parameters: map[string]interface{}{"milliseconds": 10.0}, Properties: map[string]interface{}{}},
+		"c": {Id: "c", Name: "Custom", Child: "b"},
+	}}
+	applied, err := config.MigrateTree(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := migrationNames(applied); !reflect.DeepEqual(names, []string{"parameters-to-properties", "node-categories", "parallel-policies"}) {
+		t.Errorf("applied = %v", applied)
+	}
+	if applied[0].From != config.UnversionedVersion || applied[2].To != config.LatestVersion || applied[0].Tree != "old" {
+		t.Errorf("applied = %v", applied)
+	}
+	if tree.Version != config.LatestVersion {
+		t.Errorf("version = %q", tree.Version)
+	}
+	wait := tree.Nodes["b"]
+	if wait.Parameters != nil || wait.Properties["milliseconds"] != 10.0 || wait.Category != "action" {
+		t.Errorf("wait = %+v", wait)
+	}
+	if c := tree.Nodes["a"].Category; c != "composite" {
+		t.Errorf("sequence category = %q", c)
+	}
+	if c := tree.Nodes["c"].Category; c != "decorator" {
+		t.Errorf("custom category = %q", c)
+	}
+}
+
+func TestMigrateKeepsProperties(t *testing.T) {
+	tree := &config.BTTreeCfg{Version: config.UnversionedVersion, Nodes: map[string]config.BTNodeCfg{
+		"a": {Id: "a", Name: "Wait", Category: "action",
+			Parameters: map[string]interface{}{"milliseconds": 10.0},
+			Properties: map[string]interface{}{"milliseconds": 20.0}},
+	}}
+	if _, err := config.MigrateTree(tree); err != nil {
+		t.Fatal(err)
+	}
+	if got := tree.Nodes["a"].Properties["milliseconds"]; got != 20.0 {
+		t.Errorf("milliseconds = %v, want the property to win", got)
+	}
+}
+
+func TestMigrateLatestTree(t *testing.T) {
+	tree := &config.BTTreeCfg{Version: config.LatestVersion}
+	applied, err := config.MigrateTree(tree)
+	if err != nil || len(applied) != 0 || tree.Version != config.LatestVersion {
+		t.Errorf("applied = %v, %v, version %q", applied, err, tree.Version)
+	}
+}
+
+func TestRenameNodesMigration(t *testing.T) {
+	config.RegisterMigration(config.RenameNodesMigration("rename-test", "9.0.0", "9.0.1", map[string]string{"Delay": "Wait"}))
+	tree := &config.BTTreeCfg{Version: "9.0.0", Nodes: map[string]config.BTNodeCfg{
+		"a": {Id: "a", Name: "Delay", Title: "Delay"},
+		"b": {Id: "b", Name: "Delay", Title: "wait a bit"},
+		"c": {Id: "c", Name: "Log"},
+	}}
+	applied, err := config.MigrateTree(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || tree.Version != "9.0.1" {
+		t.Errorf("applied = %v, version %q", applied, tree.Version)
+	}
+	if a := tree.Nodes["a"]; a.Name != "Wait" || a.Title != "Wait" {
+		t.Errorf("a = %+v", a)
+	}
+	if b := tree.Nodes["b"]; b.Name != "Wait" || b.Title != "wait a bit" {
+		t.Errorf("b = %+v", b)
+	}
+	if c := tree.Nodes["c"]; c.Name != "Log" {
+		t.Errorf("c = %+v", c)
+	}
+}
+
+func TestRegisterMigrationConflicts(t *testing.T) {
+	mustPanic := func(name string, m config.Migration) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: RegisterMigration did not panic", name)
+			}
+		}()
+		config.RegisterMigration(m)
+	}
+	mustPanic("same version", config.Migration{Name: "noop", From: "9.1.0", To: "9.1.0"})
+	mustPanic("duplicate start", config.Migration{Name: "again", From: config.UnversionedVersion, To: "9.1.1"})
+}
+
+func TestMigrationCycle(t *testing.T) {
+	noop := func(tree *config.BTTreeCfg) error { return nil }
+	config.RegisterMigration(config.Migration{Name: "up", From: "9.2.0", To: "9.2.1", Tree: noop})
+	config.RegisterMigration(config.Migration{Name: "down", From: "9.2.1", To: "9.2.0", Tree: noop})
+	_, err := config.MigrateTree(&config.BTTreeCfg{Title: "t", Version: "9.2.0"})
+	if err == nil || err.Error() != "tree t: migration cycle at version 9.2.0" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestMigrationError(t *testing.T) {
+	config.RegisterMigration(config.NodeMigration("fail", "9.3.0", "9.3.1", func(node *config.BTNodeCfg) error {
+		return errors.New("bad node")
+	}))
+	tree := &config.BTTreeCfg{Title: "t", Version: "9.3.0", Nodes: map[string]config.BTNodeCfg{"a": {Id: "a"}}}
+	_, err := config.MigrateTree(tree)
+	if err == nil || err.Error() != "migration fail of tree t: node a: bad node" {
+		t.Errorf("err = %v", err)
+	}
+	if tree.Version != "9.3.0" {
+		t.Errorf("version = %q, want it unchanged", tree.Version)
+	}
+}
+
+func TestMigrateProject(t *testing.T) {
+	project := &config.BTProjectCfg{Version: "0.2.0", Trees: []config.BTTreeCfg{
+		{Title: "inherits", Nodes: map[string]config.BTNodeCfg{"a": {Id: "a", Name: "Log"}}},
+		{Title: "own", Version: config.UnversionedVersion},
+		{Title: "latest", Version: config.LatestVersion},
+	}}
+	applied, err := config.MigrateProject(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trees []string
+	for _, a := range applied {
+		trees = append(trees, a.Tree+":"+a.Name)
+	}
+	want := []string{
+		"inherits:node-categories", "inherits:parallel-policies",
+		"own:parameters-to-properties", "own:node-categories", "own:parallel-policies",
+	}
+	if !reflect.DeepEqual(trees, want) {
+		t.Errorf("applied = %v, want %v", trees, want)
+	}
+	if project.Version != config.LatestVersion {
+		t.Errorf("project version = %q", project.Version)
+	}
+	for _, tree := range project.Trees {
+		if tree.Version != config.LatestVersion {
+			t.Errorf("tree %s version = %q", tree.Title, tree.Version)
+		}
+	}
+	if c := project.Trees[0].Nodes["a"].Category; c != "action" {
+		t.Errorf("category = %q", c)
+	}
+}
+
+func TestLoadTreeTextMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.tree")
+	src := "tree \"old\" @version=0.1.0\n  Sequence\n    Log\n"
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tree, applied, err := config.LoadTreeTextCfgReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Version != config.LatestVersion || len(applied) != 3 {
+		t.Errorf("version %q, applied %v", tree.Version, applied)
+	}
+	if text, _ := config.FormatTreeText(tree); strings.Contains(text, "@version") {
+		t.Errorf("migrated tree still prints a version:\n%s", text)
+	}
+	if tree, ok := config.LoadTreeTextCfg(path); !ok || tree.Version != config.LatestVersion {
+		t.Errorf("LoadTreeTextCfg = %+v, %v", tree, ok)
+	}
+}
+
+func TestParallelPoliciesMigration(t *testing.T) {
+	tree := &config.BTTreeCfg{Version: "0.3.0", Nodes: map[string]config.BTNodeCfg{
+		"old":  {Id: "old", Name: "Parallel", Properties: map[string]interface{}{"fail_cond": 0.0}},
+		"set":  {Id: "set", Name: "Parallel", Properties: map[string]interface{}{"successThreshold": 1.0}},
+		"full": {Id: "full", Name: "Parallel", Properties: map[string]interface{}{"failureThreshold": 2.0, "cancelRemaining": true}},
+		"bare": {Id: "bare", Name: "Parallel"},
+		"seq":  {Id: "seq", Name: "Sequence"},
+	}}
+	if _, err := config.MigrateTree(tree); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]interface{}{
+		"old":  {"fail_cond": 0.0, "finishThreshold": 1.0, "cancelRemaining": true},
+		"set":  {"successThreshold": 1.0, "failureThreshold": 0.0, "cancelRemaining": false},
+		"full": {"failureThreshold": 2.0, "cancelRemaining": true},
+		"bare": {"finishThreshold": 1.0, "cancelRemaining": true},
+		"seq":  nil,
+	}
+	for id, w := range want {
+		if got := tree.Nodes[id].Properties; !reflect.DeepEqual(got, w) {
+			t.Errorf("%s properties = %v, want %v", id, got, w)
+		}
+	}
+}
@@ -15,19 +15,30 @@ type RawProjectCfg struct {
 
 //加载原生工程
 func LoadRawProjectCfg(path string) (*RawProjectCfg, bool) {
+	project, _, err := LoadRawProjectCfgReport(path)
+	if err != nil {
+		fmt.Println("LoadRawProjectCfg fail:", err)
+		return nil, false
+	}
+
+	//fmt.Println("load tree:", tree.Title, " nodes:", len(tree.Nodes))
+	return project, true
+}
 
+//加载原生工程并升级到最新版本,返回执行的迁移
+func LoadRawProjectCfgReport(path string) (*RawProjectCfg, []AppliedMigration, error) {
 	var project RawProjectCfg
 	file, err := ioutil.ReadFile(path)
 	if err != nil {
-		fmt.Println("LoadRawProjectCfg fail:", err)
-		return nil, false
+		return nil, nil, err
 	}
 	err = json.Unmarshal(file, &project)
 	if err != nil {
-		fmt.Println("LoadRawProjectCfg fail, ummarshal:", err, len(file))
-		return nil, false
+		return nil, nil, fmt.Errorf("ummarshal %s: %v", path, err)
 	}
-
-	//fmt.Println("load tree:", tree.Title, " nodes:", len(tree.Nodes))
-	return &project, true
+	applied, err := MigrateProject(&project.Data)
+	if err != nil {
+		return nil, applied, err
+	}
+	return &project, applied, nil
 }
@@ -40,8 +40,6 @@ func (a *Action) Initialize(params *config.BTNodeCfg) {
 	//a.id = b3.CreateUUID()
 	a.BaseNode.Initialize(params)
 	//a.BaseNode.IBaseWorker = a
-	a.parameters = make(map[string]interface{})
-	a.properties = make(map[string]interface{})
 }
 
 func (a *Action) GetClass() string {
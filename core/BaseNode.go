@@ -12,6 +12,8 @@ type IBaseWrapper interface {
 	_tick(tick Ticker) b3.Status
 	_close(tick Ticker)
 	_exit(tick Ticker)
+	_compile(tree *BehaviorTree, index int)
+	_compiled() (*BehaviorTree, int)
 }
 type IBaseNode interface {
 	IBaseWrapper
@@ -23,6 +25,7 @@ type IBaseNode interface {
 	Initialize(params *config.BTNodeCfg)
 	GetCategory() string
 	Execute(tick Ticker) b3.Status
+	IsOpen(tick Ticker) bool
 	GetName() string
 	GetTitle() string
 	GetParent() IBaseNode
@@ -126,6 +129,10 @@ type BaseNode struct {
 	parent IBaseNode
 
 	treeID string
+
+	//编译后所属的树和节点编号
+	owner *BehaviorTree
+	index int
 }
 
 func (n *BaseNode) Ctor() {
@@ -134,8 +141,12 @@ func (n *BaseNode) Ctor() {
 func (n *BaseNode) SetName(name string) {
 	n.name = name
 }
-func (n *BaseNode) SetTitle(name string) {
-	n.name = name
+func (n *BaseNode) SetTitle(title string) {
+	n.title = title
+}
+
+func (n *BaseNode) SetID(id string) {
+	n.id = id
 }
 
 func (n *BaseNode) SetBaseNodeWorker(worker IBaseWorker) {
@@ -186,6 +197,14 @@ func (n *BaseNode) GetTitle() string {
 	return n.title
 }
 
+func (n *BaseNode) GetDescription() string {
+	return n.description
+}
+
+func (n *BaseNode) GetProperties() map[string]interface{} {
+	return n.properties
+}
+
 func (n *BaseNode) GetParent() IBaseNode {
 	return n.parent
 }
@@ -236,13 +255,24 @@ func (n *BaseNode) _execute(tick Ticker) b3.Status {
 	n._enter(tick)
 
 	// OPEN
-	if !tick.Blackboard().GetBool("isOpen", tick.GetTree().id, n.id) {
+	state := n.state(tick)
+	if state != nil {
+		if !state.open {
+			n._open(tick)
+		}
+	} else if !tick.Blackboard().GetBool("isOpen", tick.GetTree().id, n.id) {
 		n._open(tick)
 	}
 
 	// TICK
 	var status = n._tick(tick)
 
+	if state != nil {
+		state.status = status
+	} else if tick.GetTree().trackStatus {
+		tick.Blackboard().Set("lastStatus", status, tick.GetTree().id, n.id)
+	}
+
 	// CLOSE
 	if status != b3.RUNNING {
 		n._close(tick)
@@ -257,6 +287,71 @@ func (n *BaseNode) Execute(tick Ticker) b3.Status {
 	return n._execute(tick)
 }
 
+//节点对当前tick的黑板是否处于打开状态
+func (n *BaseNode) IsOpen(tick Ticker) bool {
+	if state := n.state(tick); state != nil {
+		return state.open
+	}
+	return tick.Blackboard().GetBool("isOpen", tick.GetTree().id, n.id)
+}
+
+/**
+ * Returns the value of `key` in the memory of the nearest ancestor of a
+ * node that has it, in the scope of the ticked tree, or nil. Nodes use it
+ * to find what an ancestor shares with its branch, such as the cancel
+ * context of Parallel.
+ *
+ * @method AncestorValue
+ * @param {Tick} tick A tick instance.
+ * @param {BaseNode} node The node.
+ * @param {String} key The key in the ancestor memory.
+ * @return {Object} The value or nil.
+**/
+func AncestorValue(tick Ticker, node IBaseNode, key string) interface{} {
+	for parent := node.GetParent(); parent != nil; parent = parent.GetParent() {
+		if v := tick.Blackboard().Get(key, tick.GetTree().GetID(), parent.GetID()); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+/**
+ * Closes an open node and its open descendants, deepest first, calling
+ * their `close` callbacks. Composites use it to stop a running child that
+ * will not be ticked again, e.g. when the result is decided before all the
+ * children finished.
+ *
+ * @method CloseNode
+ * @param {Tick} tick A tick instance.
+ * @param {BaseNode} node The node to close.
+**/
+func CloseNode(tick Ticker, node IBaseNode) {
+	switch tn := node.(type) {
+	case *SubTree:
+		if sub := tn.GetSubTree(); sub != nil && sub.root != nil {
+			tick.pushSubtreeNode(tn)
+			CloseNode(tick, sub.root)
+			tick.popSubtreeNode()
+		}
+	default:
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			comp := node.(IComposite)
+			for i := comp.GetChildCount() - 1; i >= 0; i-- {
+				CloseNode(tick, comp.GetChild(i))
+			}
+		case b3.DECORATOR:
+			if child := node.(IDecorator).GetChild(); child != nil {
+				CloseNode(tick, child)
+			}
+		}
+	}
+	if node.IsOpen(tick) {
+		node._close(tick)
+	}
+}
+
 /**
  * Wrapper for enter method.
  * @method _enter
@@ -276,7 +371,11 @@ func (n *BaseNode) _enter(tick Ticker) {
 **/
 func (n *BaseNode) _open(tick Ticker) {
 	tick._openNode(n)
-	tick.Blackboard().Set("isOpen", true, tick.GetTree().id, n.id)
+	if state := n.state(tick); state != nil {
+		state.open = true
+	} else {
+		tick.Blackboard().Set("isOpen", true, tick.GetTree().id, n.id)
+	}
 	n.OnOpen(tick)
 }
 
@@ -300,7 +399,11 @@ func (n *BaseNode) _tick(tick Ticker) b3.Status {
 **/
 func (n *BaseNode) _close(tick Ticker) {
 	tick._closeNode(n)
-	tick.Blackboard().Set("isOpen", false, tick.GetTree().id, n.id)
+	if state := n.state(tick); state != nil {
+		state.open = false
+	} else {
+		tick.Blackboard().Set("isOpen", false, tick.GetTree().id, n.id)
+	}
 	n.OnClose(tick)
 }
 
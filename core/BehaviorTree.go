@@ -1,7 +1,6 @@
 package core
 
 import (
-	"fmt"
 	b3 "github.com/magicsea/behavior3go"
 	"github.com/magicsea/behavior3go/config"
 )
@@ -111,7 +110,29 @@ type BehaviorTree struct {
 	**/
 	debug interface{}
 
+	/**
+	 * The project the tree was loaded from, used to resolve subtrees.
+	 * @property {Project} project
+	 * @readonly
+	**/
+	project *Project
+
 	dumpInfo *config.BTTreeCfg
+
+	//内联子树后节点的来源
+	sources config.SourceMap
+
+	//编译后按编号排列的节点
+	nodes []IBaseNode
+
+	//生成节点ID,默认为PathIDGenerator
+	idGenerator IDGenerator
+
+	//宽松加载时被替换的节点
+	substitutions []Substitution
+
+	//未编译的树是否在节点内存里记录lastStatus
+	trackStatus bool
 }
 
 func NewBeTree() *BehaviorTree {
@@ -126,7 +147,7 @@ func NewBeTree() *BehaviorTree {
  * @construCtor
 **/
 func (t *BehaviorTree) Initialize() {
-	t.id = CreateUUID()
+	t.id = ""
 	t.title = "The behavior tree"
 	t.description = "Default description"
 	t.properties = make(map[string]interface{})
@@ -146,10 +167,27 @@ func (t *BehaviorTree) SetDebug(debug interface{}) {
 	t.debug = debug
 }
 
+/**
+ * Records the status of the last tick of every node as `lastStatus` in the
+ * node memory, for `Fprint`. It costs a blackboard write per node and per
+ * tick, so it is off by default. Compiled trees always keep the status in
+ * their node state.
+ *
+ * @method SetTrackStatus
+ * @param {Boolean} track Whether to record the status.
+**/
+func (t *BehaviorTree) SetTrackStatus(track bool) {
+	t.trackStatus = track
+}
+
 func (t *BehaviorTree) GetRoot() IBaseNode {
 	return t.root
 }
 
+func (t *BehaviorTree) GetProject() *Project {
+	return t.project
+}
+
 /**
  * This method loads a Behavior Tree from a data structure, populating this
  * object with the provided data. Notice that, the data structure must
@@ -179,36 +217,59 @@ func (t *BehaviorTree) GetRoot() IBaseNode {
  * @param {Object} [names] A namespace or dict containing custom nodes.
 **/
 func (t *BehaviorTree) Load(data *config.BTTreeCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps) {
+	t.LoadWithOptions(data, maps, extMaps, LoadOptions{})
+}
+
+func (t *BehaviorTree) load(data *config.BTTreeCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps, opts LoadOptions) {
+	t.id = data.ID
+	if t.id == "" {
+		t.id = data.Title
+	}
+	if t.id == "" {
+		t.id = anonymousTreeID(data.Root)
+	}
 	t.title = data.Title             // || t.title;
 	t.description = data.Description // || t.description;
 	t.properties = data.Properties   // || t.properties;
 	t.dumpInfo = data
+	t.sources = nil
+	t.nodes = nil
+	t.substitutions = nil
 	nodes := make(map[string]IBaseNode)
 	// Create the node list (without connection between them)
 	for id, s := range data.Nodes {
 		spec := &s
-		var node IBaseNode
-
-		if spec.Category == "tree" {
-			node = new(SubTree)
-		} else {
-			if extMaps != nil && extMaps.CheckNode(spec.Name) {
-				node = extMaps.GetNode(spec.Name)()
-			} else if creator, ok := maps[spec.Name]; ok {
-				node = creator()
+		if spec.Id == "" {
+			spec.Id = id
+		}
+		node, err := createNode(spec, maps, extMaps)
+		if err != nil {
+			if !opts.Lenient {
+				panic("BehaviorTree.load: " + err.Error() + ",name:" + spec.Name + ",title:" + spec.Title)
 			}
+			// replace the node, the rest of the tree still runs
+			node = t.substitute(spec, opts.Placeholder, err.Error())
 		}
-
-		if node == nil {
-			// Invalid node name
-			panic("BehaviorTree.load: Invalid node name:" + spec.Name + ",title:" + spec.Title)
+		node.SetBaseNodeWorker(node.(IBaseWorker))
+		node.SetTreeID(t.id)
+		if st, ok := node.(*SubTree); ok {
+			st.project = t.project
 		}
-
-		node.Ctor()
-		node.Initialize(spec)
+		nodes[id] = node
+	}
+	// a child or root that is not in the node list gets a placeholder too
+	find := func(id string) IBaseNode {
+		if node, ok := nodes[id]; ok {
+			return node
+		}
+		if !opts.Lenient {
+			panic("BehaviorTree.load: node " + id + " not found")
+		}
+		node := t.substitute(&config.BTNodeCfg{Id: id}, opts.Placeholder, "node not found")
 		node.SetBaseNodeWorker(node.(IBaseWorker))
-		node.SetTreeID(data.ID)
+		node.SetTreeID(t.id)
 		nodes[id] = node
+		return node
 	}
 
 	// Connect the nodes
@@ -217,19 +278,31 @@ func (t *BehaviorTree) Load(data *config.BTTreeCfg, maps map[string]NodeCreator,
 		if node.GetCategory() == b3.COMPOSITE && spec.Children != nil {
 			for i := 0; i < len(spec.Children); i++ {
 				cid := spec.Children[i]
-				child := nodes[cid]
+				child := find(cid)
 				comp := node.(IComposite)
 				comp.AddChild(child)
 				child.SetParent(node)
 			}
 		} else if node.GetCategory() == b3.DECORATOR && len(spec.Child) > 0 {
 			dec := node.(IDecorator)
-			child := nodes[spec.Child]
+			child := find(spec.Child)
 			dec.SetChild(child)
 			child.SetParent(dec)
 		}
 	}
 	t.root = nodes[data.Root]
+	if t.root == nil && data.Root != "" && opts.Lenient {
+		t.root = find(data.Root)
+	}
+	sortSubstitutions(t.substitutions)
+}
+
+//宽松加载时用占位节点替换节点并记录
+func (t *BehaviorTree) substitute(spec *config.BTNodeCfg, mode PlaceholderMode, reason string) IBaseNode {
+	t.substitutions = append(t.substitutions, Substitution{
+		Tree: t.id, NodeID: spec.Id, Name: spec.Name, Title: spec.Title, Reason: reason,
+	})
+	return newPlaceholder(spec, mode)
 }
 
 /**
@@ -273,64 +346,46 @@ func (t *BehaviorTree) Tick(tick Ticker, blackboard *Blackboard) b3.Status {
 	}
 
 	/* CREATE A TICK OBJECT */
+	tick.reset()
 	tick.setTree(t)
 	tick.setDebug(t.debug)
 	tick.setBlackboard(blackboard)
+	tick.setNodeStates(t, t.nodeStates(blackboard))
 
 	/* TICK NODE */
 	var state = t.root._execute(tick)
 
 	/* CLOSE NODES FROM LAST TICK, IF NEEDED */
-	var lastOpenNodes = blackboard._getTreeData(t.id).OpenNodes
-	var currOpenNodes []IBaseNode
-	currOpenNodes = append(currOpenNodes, tick.openNodes()...)
-
-	// does not close if it is still open in t tick
-	var start = 0
-	for i := 0; i < MinInt(len(lastOpenNodes), len(currOpenNodes)); i++ {
-		start = i + 1
-		if lastOpenNodes[i] != currOpenNodes[i] {
-			break
+	var treeData = blackboard._getTreeData(t.id)
+	var lastOpenNodes = treeData.OpenNodes
+	var currOpenNodes = tick.openNodes()
+
+	// close the nodes that are not open in this tick any more, the open
+	// nodes are not always a prefix: children of a parallel node may finish
+	// in any order
+	for i := len(lastOpenNodes) - 1; i >= 0; i-- {
+		node := lastOpenNodes[i]
+		//本次tick已经关闭的节点不再关闭
+		if node.IsOpen(tick) && !containsNode(currOpenNodes, node) {
+			node._close(tick)
 		}
 	}
 
-	// close the nodes
-	for i := len(lastOpenNodes) - 1; i >= start; i-- {
-		lastOpenNodes[i]._close(tick)
-	}
-
 	/* POPULATE BLACKBOARD */
-	blackboard._getTreeData(t.id).OpenNodes = currOpenNodes
-	blackboard.SetTree("nodeCount", tick.nodeCount(), t.id)
+	// the closed nodes are not used any more, reuse the slice
+	treeData.OpenNodes = append(lastOpenNodes[:0], currOpenNodes...)
+	if count := tick.nodeCount(); blackboard.Get("nodeCount", t.id, "") != count {
+		blackboard.SetTree("nodeCount", count, t.id)
+	}
 
 	return state
 }
 
-func (t *BehaviorTree) Print() {
-	printNode(t.root, 0)
-}
-
-func printNode(root IBaseNode, blk int) {
-
-	for i := 0; i < blk; i++ {
-		fmt.Print(" ") //缩进
-	}
-	fmt.Print("|—", root.GetTitle())
-
-	if root.GetCategory() == b3.DECORATOR {
-		dec := root.(IDecorator)
-		if dec.GetChild() != nil {
-			printNode(dec.GetChild(), blk+3)
-		}
-	}
-
-	fmt.Println("")
-	if root.GetCategory() == b3.COMPOSITE {
-		comp := root.(IComposite)
-		if comp.GetChildCount() > 0 {
-			for i := 0; i < comp.GetChildCount(); i++ {
-				printNode(comp.GetChild(i), blk+3)
-			}
+func containsNode(nodes []IBaseNode, node IBaseNode) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
 		}
 	}
+	return false
 }
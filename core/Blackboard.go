@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sync"
 )
@@ -81,21 +82,33 @@ func (m *Memory) Remove(key string) {
 	m.memory.Delete(key)
 }
 
+//遍历所有的值,f返回false时停止
+func (m *Memory) Range(f func(key string, value interface{}) bool) {
+	m.memory.Range(func(k, v interface{}) bool {
+		return f(k.(string), v)
+	})
+}
+
 //------------------------TreeMemory-------------------------
 type TreeMemory struct {
 	memory     *Memory
 	treeData   *TreeData
 	nodeMemory *sync.Map
+	//编译后的树的节点状态,按节点编号索引
+	states []nodeState
 }
 
 func NewTreeMemory() *TreeMemory {
-	return &TreeMemory{NewMemory(), NewTreeData(), &sync.Map{}}
+	return &TreeMemory{NewMemory(), NewTreeData(), &sync.Map{}, nil}
 }
 
 //------------------------Blackboard-------------------------
 type Blackboard struct {
 	baseMemory *Memory
 	treeMemory *sync.Map
+	//节点使用的随机数,见SetSeed
+	randMu sync.Mutex
+	rand   *rand.Rand
 }
 
 func NewBlackboard() *Blackboard {
@@ -107,6 +120,7 @@ func NewBlackboard() *Blackboard {
 func (b *Blackboard) Initialize() {
 	b.baseMemory = NewMemory()
 	b.treeMemory = &sync.Map{}
+	b.rand = nil
 }
 
 /**
@@ -146,6 +160,18 @@ func (b *Blackboard) _getNodeMemory(treeMemory *TreeMemory, nodeScope string) *M
 	return memory
 }
 
+//查找节点的内存,不存在时返回nil
+func (b *Blackboard) _findNodeMemory(treeScope, nodeScope string) *Memory {
+	tm, ok := b.treeMemory.Load(treeScope)
+	if !ok {
+		return nil
+	}
+	if rs, ok := tm.(*TreeMemory).nodeMemory.Load(nodeScope); ok {
+		return rs.(*Memory)
+	}
+	return nil
+}
+
 /**
  * Internal method to retrieve the context memory. If treeScope and
  * nodeScope are provided, this method returns the per node per tree
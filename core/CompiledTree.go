@@ -0,0 +1,104 @@
+package core
+
+import (
+	b3 "github.com/magicsea/behavior3go"
+)
+
+/**
+ * Compiling a tree gives every node a dense index, in depth-first order
+ * from the root. The open flag and the last status of the nodes of a
+ * compiled tree are kept per agent in a slice of the tree memory, indexed
+ * by node, instead of the `isOpen` and `lastStatus` keys of the node
+ * memory, so ticking does not go through `sync.Map` for them and does not
+ * allocate:
+ *
+ *     tree := loader.CreateBevTreeFromConfig(cfg, maps)
+ *     tree.Compile()
+ *     tick := core.NewTick()
+ *     for {
+ *       tree.Tick(tick, board)
+ *     }
+ *
+ * Nodes of subtrees that are not inlined keep the blackboard bookkeeping,
+ * load with `LoadOptions{InlineSubtrees: true, Compile: true}` to compile
+ * the whole tree.
+ *
+ * @class CompiledTree
+**/
+
+//编译后每个节点在每个黑板里的状态
+type nodeState struct {
+	open   bool
+	status b3.Status
+}
+
+//给节点编号,之后节点的打开状态保存在按编号索引的数组里
+func (t *BehaviorTree) Compile() {
+	t.nodes = t.nodes[:0]
+	if t.root == nil {
+		return
+	}
+	var walk func(node IBaseNode)
+	walk = func(node IBaseNode) {
+		node._compile(t, len(t.nodes))
+		t.nodes = append(t.nodes, node)
+		if _, ok := node.(*SubTree); ok {
+			return
+		}
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			comp := node.(IComposite)
+			for i := 0; i < comp.GetChildCount(); i++ {
+				walk(comp.GetChild(i))
+			}
+		case b3.DECORATOR:
+			if child := node.(IDecorator).GetChild(); child != nil {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+}
+
+func (t *BehaviorTree) IsCompiled() bool {
+	return len(t.nodes) > 0
+}
+
+//编译后的节点数
+func (t *BehaviorTree) GetNodeCount() int {
+	return len(t.nodes)
+}
+
+//按编号获取节点
+func (t *BehaviorTree) GetNodeByIndex(index int) IBaseNode {
+	return t.nodes[index]
+}
+
+//黑板里这棵树的节点状态,节点数变化时重新分配
+func (t *BehaviorTree) nodeStates(blackboard *Blackboard) []nodeState {
+	if !t.IsCompiled() {
+		return nil
+	}
+	tm := blackboard._getTreeMemory(t.id)
+	if len(tm.states) != len(t.nodes) {
+		tm.states = make([]nodeState, len(t.nodes))
+	}
+	return tm.states
+}
+
+//编译后的节点状态,不属于当前树时返回nil
+func (n *BaseNode) state(tick Ticker) *nodeState {
+	if n.owner == nil {
+		return nil
+	}
+	return tick.nodeState(n.owner, n.index)
+}
+
+func (n *BaseNode) _compile(tree *BehaviorTree, index int) {
+	n.owner = tree
+	n.index = index
+}
+
+func (n *BaseNode) _compiled() (*BehaviorTree, int) {
+	return n.owner, n.index
+}
@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"reflect"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+const benchmarkTree = `tree "benchmark"
+  Priority
+    Sequence
+      Failer
+      Succeeder
+    Sequence
+      Inverter
+        Failer
+      Succeeder
+      Inverter
+        Succeeder
+    Sequence
+      Succeeder
+      Inverter
+        Failer
+      Succeeder
+      Succeeder
+`
+
+//返回ticks次RUNNING后成功,记录打开和关闭
+type busy struct {
+	core.Action
+	Ticks  int `b3:"ticks"`
+	events *[]string
+}
+
+func (b *busy) OnOpen(tick core.Ticker) {
+	*b.events = append(*b.events, "open "+b.GetID())
+	tick.Blackboard().Set("left", b.Ticks, tick.GetTree().GetID(), b.GetID())
+}
+
+func (b *busy) OnTick(tick core.Ticker) b3.Status {
+	left := tick.Blackboard().GetInt("left", tick.GetTree().GetID(), b.GetID())
+	if left > 0 {
+		tick.Blackboard().Set("left", left-1, tick.GetTree().GetID(), b.GetID())
+		return b3.RUNNING
+	}
+	return b3.SUCCESS
+}
+
+func (b *busy) OnClose(tick core.Ticker) {
+	*b.events = append(*b.events, "close "+b.GetID())
+}
+
+func loadTextTree(t testing.TB, src string, events *[]string) *core.BehaviorTree {
+	t.Helper()
+	cfg, err := config.ParseTreeText(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Busy", func() core.IBaseNode { return &busy{events: events} })
+	return loader.CreateBevTreeFromConfig(cfg, maps)
+}
+
+func TestCompiledTreeMatchesPlain(t *testing.T) {
+	trees := map[string]string{
+		"benchmark": benchmarkTree,
+		"memsequence": `tree "t"
+  MemSequence
+    Busy @id=a ticks=2
+    Inverter
+      Failer
+    Busy @id=b ticks=1
+`,
+		//the Limiter branch wins after three ticks and closes the running Busy
+		"preempted": `tree "t"
+  Priority
+    Inverter
+      Limiter maxLoop=3
+        Succeeder
+    Sequence
+      Busy @id=a ticks=1
+      Busy @id=b ticks=10
+`,
+		"repeater": `tree "t"
+  Repeater maxLoop=3
+    Sequence
+      Busy @id=a ticks=1
+      Succeeder
+`,
+	}
+	for name, src := range trees {
+		var plainEvents, compiledEvents []string
+		plain := loadTextTree(t, src, &plainEvents)
+		compiled := loadTextTree(t, src, &compiledEvents)
+		compiled.Compile()
+		if !compiled.IsCompiled() || plain.IsCompiled() {
+			t.Fatalf("%s: compiled %v, plain %v", name, compiled.IsCompiled(), plain.IsCompiled())
+		}
+		//two agents ticked in turns must not share node states
+		plainBoards := []*core.Blackboard{core.NewBlackboard(), core.NewBlackboard()}
+		compiledBoards := []*core.Blackboard{core.NewBlackboard(), core.NewBlackboard()}
+		tick := core.NewTick()
+		for i := 0; i < 12; i++ {
+			agent := i / 3 % 2
+			want := plain.Tick(core.NewTick(), plainBoards[agent])
+			if got := compiled.Tick(tick, compiledBoards[agent]); got != want {
+				t.Errorf("%s tick %d: compiled %v, plain %v", name, i, got, want)
+			}
+		}
+		if !reflect.DeepEqual(plainEvents, compiledEvents) {
+			t.Errorf("%s: compiled events %v, plain %v", name, compiledEvents, plainEvents)
+		}
+	}
+}
+
+func TestCompileNumbersNodes(t *testing.T) {
+	tree := loadTextTree(t, "tree \"t\"\n  Sequence @id=a\n    Inverter @id=b\n      Failer @id=c\n    Succeeder @id=d\n", nil)
+	tree.Compile()
+	var ids []string
+	for i := 0; i < tree.GetNodeCount(); i++ {
+		ids = append(ids, tree.GetNodeByIndex(i).GetID())
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b", "c", "d"}) {
+		t.Errorf("nodes = %v", ids)
+	}
+}
+
+func benchmarkTick(b *testing.B, compile bool) {
+	tree := loadTextTree(b, benchmarkTree, nil)
+	if compile {
+		tree.Compile()
+	}
+	board := core.NewBlackboard()
+	tick := core.NewTick()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Tick(tick, board)
+	}
+}
+
+func BenchmarkTickPlain(b *testing.B) {
+	benchmarkTick(b, false)
+}
+
+func BenchmarkTickCompiled(b *testing.B) {
+	benchmarkTick(b, true)
+}
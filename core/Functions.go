@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"io"
+	"sort"
 )
 
 //生成32位md5字串
@@ -92,4 +93,14 @@ func (rsm *RegisterStructMaps) GetNode(name string) NodeCreator {
 	return nil
 }
 
+//所有注册的名字,按字母排序
+func (rsm *RegisterStructMaps) GetNames() []string {
+	names := make([]string, 0, len(rsm.nodes))
+	for name := range rsm.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type NodeCreator func() IBaseNode
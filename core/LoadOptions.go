@@ -0,0 +1,76 @@
+package core
+
+import (
+	"github.com/magicsea/behavior3go/config"
+)
+
+//加载选项
+type LoadOptions struct {
+	//加载时把子树内联到树里,不再在tick时查找子树
+	InlineSubtrees bool
+	//内联时按名字或标题查找子树,工程加载时默认在工程里查找
+	Resolve func(name string) *config.BTTreeCfg
+	//加载后编译树,见Compile
+	Compile bool
+	//找不到或创建失败的节点替换为占位节点,不再panic
+	Lenient bool
+	//占位节点的行为,默认PlaceholderFailure
+	Placeholder PlaceholderMode
+}
+
+/**
+ * Loads a tree like `Load`. With `InlineSubtrees` the referenced subtrees
+ * are copied into the tree first, see `config.FlattenTree`, and
+ * `GetNodeSource` leads the inlined nodes back to their subtree. With
+ * `Compile` the tree is compiled after loading, see `Compile`. With
+ * `Lenient` unknown nodes, nodes that fail to load and children missing
+ * from the node list are replaced by placeholders instead of panicking,
+ * see `GetSubstitutions`.
+ *
+ * Documents of older versions are upgraded first with
+ * `config.MigrateTree`, and so are the inlined subtrees.
+ *
+ * @method LoadWithOptions
+ * @param {Object} data The data structure representing a Behavior Tree.
+ * @param {Object} [names] A namespace or dict containing custom nodes.
+ * @param {LoadOptions} opts The load options.
+**/
+func (t *BehaviorTree) LoadWithOptions(data *config.BTTreeCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps, opts LoadOptions) {
+	migrateTree(data)
+	if opts.InlineSubtrees {
+		resolve := opts.Resolve
+		if resolve != nil {
+			opts.Resolve = func(name string) *config.BTTreeCfg {
+				tree := resolve(name)
+				if tree != nil {
+					migrateTree(tree)
+				}
+				return tree
+			}
+		}
+		flat, sources, err := config.FlattenTree(data, opts.Resolve)
+		if err != nil {
+			panic("BehaviorTree.load: " + err.Error())
+		}
+		t.load(flat, maps, extMaps, opts)
+		t.sources = sources
+	} else {
+		t.load(data, maps, extMaps, opts)
+	}
+	if opts.Compile {
+		t.Compile()
+	}
+}
+
+//升级到最新版本,已经是最新版本时不做改动
+func migrateTree(data *config.BTTreeCfg) {
+	if _, err := config.MigrateTree(data); err != nil {
+		panic("BehaviorTree.load: " + err.Error())
+	}
+}
+
+//内联的节点在原来的树里的位置
+func (t *BehaviorTree) GetNodeSource(id string) (config.NodeSource, bool) {
+	source, ok := t.sources[id]
+	return source, ok
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"strconv"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+/**
+ * Trees and nodes get IDs that do not change between loads, so that state
+ * saved in a blackboard can find its nodes again after a restart. A tree
+ * loaded from a config uses the IDs of the config. A tree without an ID
+ * uses its title, a tree without both uses `tree@<root id>`, so its node
+ * memory never lands in the global memory of the blackboard. A node
+ * without an ID gets one from its position:
+ * the ID of its parent followed by its child index, `<tree id>/0` for the
+ * root.
+ *
+ *     tree := core.NewBeTree()
+ *     tree.SetID("patrol")
+ *     tree.SetRoot(sequence) // patrol/0, patrol/0/0, patrol/0/1, ...
+ *
+ * @class NodeID
+**/
+
+//生成没有ID的节点的ID,parentID为父节点ID,根节点为树的ID
+type IDGenerator func(parentID string, index int, node IBaseNode) string
+
+//父节点ID加上子节点序号
+func PathIDGenerator(parentID string, index int, node IBaseNode) string {
+	return parentID + "/" + strconv.Itoa(index)
+}
+
+//没有ID和标题的树的ID,空的树ID会让节点记忆落到全局黑板里
+func anonymousTreeID(rootID string) string {
+	if rootID == "" {
+		return "tree"
+	}
+	return "tree@" + rootID
+}
+
+//设置生成节点ID的方法,在SetRoot之前调用
+func (t *BehaviorTree) SetIDGenerator(gen IDGenerator) {
+	t.idGenerator = gen
+}
+
+func (t *BehaviorTree) SetID(id string) {
+	t.id = id
+}
+
+func (t *BehaviorTree) SetTitle(title string) {
+	t.title = title
+}
+
+/**
+ * Sets the root of a tree built in code. Nodes without an ID get one from
+ * the ID generator, and every node is attached to its parent and to the
+ * tree.
+ *
+ * @method SetRoot
+ * @param {BaseNode} root The root node.
+**/
+func (t *BehaviorTree) SetRoot(root IBaseNode) {
+	if t.id == "" {
+		t.id = t.title
+	}
+	if t.id == "" {
+		t.id = anonymousTreeID(root.GetID())
+	}
+	gen := t.idGenerator
+	if gen == nil {
+		gen = PathIDGenerator
+	}
+	var attach func(node, parent IBaseNode, parentID string, index int)
+	attach = func(node, parent IBaseNode, parentID string, index int) {
+		if node.GetID() == "" {
+			if n, ok := node.(interface{ SetID(id string) }); ok {
+				n.SetID(gen(parentID, index, node))
+			}
+		}
+		if node.GetBaseNodeWorker() == nil {
+			if worker, ok := node.(IBaseWorker); ok {
+				node.SetBaseNodeWorker(worker)
+			}
+		}
+		node.SetParent(parent)
+		node.SetTreeID(t.id)
+		if _, ok := node.(*SubTree); ok {
+			return
+		}
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			comp := node.(IComposite)
+			for i := 0; i < comp.GetChildCount(); i++ {
+				attach(comp.GetChild(i), node, node.GetID(), i)
+			}
+		case b3.DECORATOR:
+			if child := node.(IDecorator).GetChild(); child != nil {
+				attach(child, node, node.GetID(), 0)
+			}
+		}
+	}
+	attach(root, nil, t.id, 0)
+	t.root = root
+	t.nodes = nil
+}
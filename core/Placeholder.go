@@ -0,0 +1,167 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+)
+
+/**
+ * Placeholders stand in for nodes that cannot be loaded, when a tree is
+ * loaded with `LoadOptions{Lenient: true}`: nodes added in the editor but
+ * not implemented yet, nodes with invalid properties, or children and
+ * roots that are missing from the node list. A placeholder
+ * keeps the category and the children of the node it replaces, but never
+ * ticks the children.
+ *
+ *     tree.LoadWithOptions(cfg, maps, extMaps, core.LoadOptions{
+ *       Lenient:     true,
+ *       Placeholder: core.PlaceholderLogFailure,
+ *     })
+ *     for _, s := range tree.GetSubstitutions() {
+ *       fmt.Println(s)
+ *     }
+ *
+ * @class Placeholder
+**/
+
+//占位节点的行为
+type PlaceholderMode int
+
+const (
+	//返回FAILURE
+	PlaceholderFailure PlaceholderMode = iota
+	//返回SUCCESS
+	PlaceholderSuccess
+	//返回ERROR
+	PlaceholderError
+	//输出日志后返回FAILURE
+	PlaceholderLogFailure
+)
+
+//被替换的节点
+type Substitution struct {
+	Tree   string
+	NodeID string
+	Name   string
+	Title  string
+	Reason string
+}
+
+func (s Substitution) String() string {
+	return fmt.Sprintf("tree %s node %s(%s): %s", s.Tree, s.Name, s.NodeID, s.Reason)
+}
+
+//加载时被替换为占位节点的节点,按节点ID排序
+func (t *BehaviorTree) GetSubstitutions() []Substitution {
+	return t.substitutions
+}
+
+//所有树的占位节点
+func (p *Project) GetSubstitutions() []Substitution {
+	var result []Substitution
+	for _, tree := range p.trees {
+		result = append(result, tree.substitutions...)
+	}
+	return result
+}
+
+//创建并初始化节点,节点不存在,属性错误或初始化panic时返回错误
+func createNode(spec *config.BTNodeCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps) (node IBaseNode, err error) {
+	if spec.Category == "tree" {
+		node = new(SubTree)
+	} else if extMaps != nil && extMaps.CheckNode(spec.Name) {
+		node = extMaps.GetNode(spec.Name)()
+	} else if creator, ok := maps[spec.Name]; ok {
+		node = creator()
+	}
+	if node == nil {
+		// Invalid node name
+		return nil, fmt.Errorf("invalid node name")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			node, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	node.Ctor()
+	if err := BindProperties(node, spec); err != nil {
+		return nil, err
+	}
+	node.Initialize(spec)
+	return node, nil
+}
+
+func newPlaceholder(spec *config.BTNodeCfg, mode PlaceholderMode) IBaseNode {
+	var node IBaseNode
+	switch {
+	case spec.Category == b3.COMPOSITE || spec.Category == "" && len(spec.Children) > 0:
+		node = &placeholderComposite{mode: mode}
+	case spec.Category == b3.DECORATOR || spec.Category == "" && spec.Child != "":
+		node = &placeholderDecorator{mode: mode}
+	case spec.Category == b3.CONDITION:
+		node = &placeholderCondition{mode: mode}
+	default:
+		node = &placeholderAction{mode: mode}
+	}
+	node.Ctor()
+	node.Initialize(spec)
+	return node
+}
+
+func placeholderTick(node IBaseNode, mode PlaceholderMode) b3.Status {
+	switch mode {
+	case PlaceholderSuccess:
+		return b3.SUCCESS
+	case PlaceholderError:
+		return b3.ERROR
+	case PlaceholderLogFailure:
+		fmt.Println("placeholder:", node.GetName(), node.GetTitle(), node.GetID())
+	}
+	return b3.FAILURE
+}
+
+type placeholderAction struct {
+	Action
+	mode PlaceholderMode
+}
+
+func (p *placeholderAction) OnTick(tick Ticker) b3.Status {
+	return placeholderTick(p, p.mode)
+}
+
+type placeholderCondition struct {
+	Condition
+	mode PlaceholderMode
+}
+
+func (p *placeholderCondition) OnTick(tick Ticker) b3.Status {
+	return placeholderTick(p, p.mode)
+}
+
+type placeholderComposite struct {
+	Composite
+	mode PlaceholderMode
+}
+
+func (p *placeholderComposite) OnTick(tick Ticker) b3.Status {
+	return placeholderTick(p, p.mode)
+}
+
+type placeholderDecorator struct {
+	Decorator
+	mode PlaceholderMode
+}
+
+func (p *placeholderDecorator) OnTick(tick Ticker) b3.Status {
+	return placeholderTick(p, p.mode)
+}
+
+func sortSubstitutions(list []Substitution) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NodeID < list[j].NodeID
+	})
+}
@@ -0,0 +1,103 @@
+package core_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+func substitutionStrings(list []core.Substitution) []string {
+	var result []string
+	for _, s := range list {
+		result = append(result, s.String())
+	}
+	return result
+}
+
+func TestLenientUnknownNodes(t *testing.T) {
+	cfg, err := config.ParseTreeText(`tree "t"
+  Sequence @id=root
+    Succeeder @id=a
+    NotImplemented @id=b
+    Inverter @id=c
+      Fancy @id=d @category=condition
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modes := []struct {
+		mode   core.PlaceholderMode
+		status b3.Status
+	}{
+		{core.PlaceholderFailure, b3.FAILURE},
+		{core.PlaceholderSuccess, b3.FAILURE},
+		{core.PlaceholderError, b3.ERROR},
+	}
+	for _, m := range modes {
+		tree, subs := loader.CreateBevTreeLenient(cfg, nil, m.mode)
+		want := []string{
+			"tree t node NotImplemented(b): invalid node name",
+			"tree t node Fancy(d): invalid node name",
+		}
+		if got := substitutionStrings(subs); !reflect.DeepEqual(got, want) {
+			t.Errorf("substitutions = %q, want %q", got, want)
+		}
+		if status := tree.Tick(core.NewTick(), core.NewBlackboard()); status != m.status {
+			t.Errorf("mode %v: status %v, want %v", m.mode, status, m.status)
+		}
+	}
+}
+
+func TestLenientMissingChildren(t *testing.T) {
+	cfg := &config.BTTreeCfg{ID: "t", Title: "t", Root: "root", Nodes: map[string]config.BTNodeCfg{
+		"root": {Id: "root", Name: "Sequence", Category: "composite", Children: []string{"a", "gone"}},
+		"a":    {Id: "a", Name: "Inverter", Category: "decorator", Child: "lost"},
+	}}
+	tree, subs := loader.CreateBevTreeLenient(cfg, nil, core.PlaceholderSuccess)
+	want := []string{"tree t node (gone): node not found", "tree t node (lost): node not found"}
+	if got := substitutionStrings(subs); !reflect.DeepEqual(got, want) {
+		t.Errorf("substitutions = %q, want %q", got, want)
+	}
+	//Inverter(SUCCESS) fails the sequence
+	if status := tree.Tick(core.NewTick(), core.NewBlackboard()); status != b3.FAILURE {
+		t.Errorf("status = %v", status)
+	}
+	root := tree.GetRoot().(core.IComposite)
+	if gone := root.GetChild(1); gone.GetID() != "gone" || gone.GetParent() != tree.GetRoot() {
+		t.Errorf("placeholder %s parent %v", gone.GetID(), gone.GetParent())
+	}
+
+	cfg.Root = "nowhere"
+	tree, subs = loader.CreateBevTreeLenient(cfg, nil, core.PlaceholderSuccess)
+	if tree.GetRoot() == nil || tree.GetRoot().GetID() != "nowhere" || len(subs) != 3 {
+		t.Errorf("root %v, substitutions %v", tree.GetRoot(), subs)
+	}
+}
+
+func TestStrictMissingChild(t *testing.T) {
+	cfg := &config.BTTreeCfg{ID: "t", Title: "t", Root: "root", Nodes: map[string]config.BTNodeCfg{
+		"root": {Id: "root", Name: "Sequence", Category: "composite", Children: []string{"gone"}},
+	}}
+	defer func() {
+		if r := recover(); fmt.Sprint(r) != "BehaviorTree.load: node gone not found" {
+			t.Errorf("panic = %v", r)
+		}
+	}()
+	loader.CreateBevTreeFromConfig(cfg, nil)
+}
+
+func TestLenientProject(t *testing.T) {
+	project := &config.BTProjectCfg{Trees: []config.BTTreeCfg{
+		{ID: "a", Title: "a", Root: "x", Nodes: map[string]config.BTNodeCfg{"x": {Id: "x", Name: "Missing"}}},
+		{ID: "b", Title: "b", Root: "y", Nodes: map[string]config.BTNodeCfg{"y": {Id: "y", Name: "Succeeder"}}},
+	}}
+	_, subs := loader.CreateProjectLenient(project, nil, core.PlaceholderLogFailure)
+	if got := substitutionStrings(subs); !reflect.DeepEqual(got, []string{"tree a node Missing(x): invalid node name"}) {
+		t.Errorf("substitutions = %q", got)
+	}
+}
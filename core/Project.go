@@ -0,0 +1,149 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+/**
+ * The Project class holds all the trees of an editor project. SubTree nodes
+ * of these trees are resolved through the project that loaded them, so
+ * several projects can live in one process.
+ *
+ *     project := core.NewProject()
+ *     project.Load(projectCfg, maps, extMaps)
+ *     tree := project.SelectedTree()
+ *
+ * @module b3
+ * @class Project
+**/
+type Project struct {
+	id       string
+	selected string
+	trees    []*BehaviorTree
+	byID     map[string]*BehaviorTree
+	byTitle  map[string]*BehaviorTree
+}
+
+func NewProject() *Project {
+	p := &Project{}
+	p.Initialize()
+	return p
+}
+
+/**
+ * Initialization method.
+ * @method Initialize
+ * @construCtor
+**/
+func (p *Project) Initialize() {
+	p.id = ""
+	p.selected = ""
+	p.trees = nil
+	p.byID = make(map[string]*BehaviorTree)
+	p.byTitle = make(map[string]*BehaviorTree)
+}
+
+/**
+ * Builds every tree of the project. Like `BehaviorTree.Load`, it panics on
+ * an invalid node, and on two trees with the same ID or title. Trees of
+ * older versions are upgraded first, see `config.MigrateProject`.
+ *
+ * @method Load
+ * @param {Object} data The project data.
+ * @param {Object} [names] A namespace or dict containing custom nodes.
+**/
+func (p *Project) Load(data *config.BTProjectCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps) {
+	p.LoadWithOptions(data, maps, extMaps, LoadOptions{})
+}
+
+/**
+ * Builds every tree of the project with the given options. Subtrees are
+ * inlined from the trees of the project unless `opts.Resolve` is set.
+ *
+ * @method LoadWithOptions
+ * @param {Object} data The project data.
+ * @param {Object} [names] A namespace or dict containing custom nodes.
+ * @param {LoadOptions} opts The load options.
+**/
+func (p *Project) LoadWithOptions(data *config.BTProjectCfg, maps map[string]NodeCreator, extMaps *RegisterStructMaps, opts LoadOptions) {
+	p.Initialize()
+	//没有版本号的树按工程的版本号升级
+	if _, err := config.MigrateProject(data); err != nil {
+		panic("Project.Load: " + err.Error())
+	}
+	p.id = data.ID
+	p.selected = data.Select
+	if opts.Resolve == nil {
+		opts.Resolve = data.FindTree
+	}
+	for i := range data.Trees {
+		tree := NewBeTree()
+		tree.project = p
+		tree.LoadWithOptions(&data.Trees[i], maps, extMaps, opts)
+		if err := p.AddTree(tree); err != nil {
+			panic("Project.Load: " + err.Error())
+		}
+	}
+}
+
+//添加一棵树,按配置的ID和标题索引,ID或标题重复时返回错误
+func (p *Project) AddTree(tree *BehaviorTree) error {
+	if _, ok := p.byID[tree.GetID()]; ok {
+		return fmt.Errorf("duplicate tree id %s", tree.GetID())
+	}
+	if tree.dumpInfo != nil && tree.dumpInfo.ID != "" {
+		if _, ok := p.byID[tree.dumpInfo.ID]; ok {
+			return fmt.Errorf("duplicate tree id %s", tree.dumpInfo.ID)
+		}
+	}
+	if _, ok := p.byTitle[tree.GetTitile()]; ok && tree.GetTitile() != "" {
+		return fmt.Errorf("duplicate tree title %s", tree.GetTitile())
+	}
+	tree.project = p
+	p.trees = append(p.trees, tree)
+	if tree.dumpInfo != nil && tree.dumpInfo.ID != "" {
+		p.byID[tree.dumpInfo.ID] = tree
+	}
+	p.byID[tree.GetID()] = tree
+	if tree.GetTitile() != "" {
+		p.byTitle[tree.GetTitile()] = tree
+	}
+	return nil
+}
+
+func (p *Project) GetID() string {
+	return p.id
+}
+
+func (p *Project) GetTrees() []*BehaviorTree {
+	return p.trees
+}
+
+func (p *Project) GetTreeByID(id string) *BehaviorTree {
+	return p.byID[id]
+}
+
+func (p *Project) GetTreeByTitle(title string) *BehaviorTree {
+	return p.byTitle[title]
+}
+
+//按ID查找,找不到再按标题查找
+func (p *Project) GetTree(key string) *BehaviorTree {
+	if tree, ok := p.byID[key]; ok {
+		return tree
+	}
+	return p.byTitle[key]
+}
+
+//编辑器里选中的树,没有则是第一棵树
+func (p *Project) SelectedTree() *BehaviorTree {
+	if tree, ok := p.byID[p.selected]; ok {
+		return tree
+	}
+	if len(p.trees) > 0 {
+		return p.trees[0]
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+func busyCfg(id, title string) config.BTTreeCfg {
+	return config.BTTreeCfg{ID: id, Title: title, Root: "a", Nodes: map[string]config.BTNodeCfg{
+		"a": {Id: "a", Name: "Busy", Category: "action", Properties: map[string]interface{}{"ticks": 1.0}},
+	}}
+}
+
+func loadProject(cfg *config.BTProjectCfg) (project *core.Project, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	var events []string
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Busy", func() core.IBaseNode { return &busy{events: &events} })
+	return loader.CreateProjectFromConfig(cfg, maps), nil
+}
+
+func TestAnonymousTreeID(t *testing.T) {
+	project, err := loadProject(&config.BTProjectCfg{Trees: []config.BTTreeCfg{busyCfg("", "")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := project.GetTrees()[0]
+	if tree.GetID() != "tree@a" {
+		t.Fatalf("tree id = %q", tree.GetID())
+	}
+	//the node memory of the tree must not go to the global memory
+	board := core.NewBlackboard()
+	if status := tree.Tick(core.NewTick(), board); status != b3.RUNNING {
+		t.Fatalf("status = %v", status)
+	}
+	if v := board.GetMem("left"); v != nil {
+		t.Errorf("global left = %v", v)
+	}
+	if v := board.Get("left", "tree@a", "a"); v != 0 {
+		t.Errorf("node left = %v", v)
+	}
+
+	code := core.NewBeTree()
+	code.SetTitle("")
+	root := &busy{}
+	root.Ctor()
+	root.SetID("r")
+	code.SetRoot(root)
+	if code.GetID() != "tree@r" {
+		t.Errorf("code-built tree id = %q", code.GetID())
+	}
+}
+
+func TestProjectDuplicateTrees(t *testing.T) {
+	cases := []struct {
+		name  string
+		trees []config.BTTreeCfg
+		err   string
+	}{
+		{"id", []config.BTTreeCfg{busyCfg("x", "a"), busyCfg("x", "b")}, "Project.Load: duplicate tree id x"},
+		{"title", []config.BTTreeCfg{busyCfg("x", "a"), busyCfg("y", "a")}, "Project.Load: duplicate tree title a"},
+		{"title as id", []config.BTTreeCfg{busyCfg("", "a"), busyCfg("", "a")}, "Project.Load: duplicate tree id a"},
+	}
+	for _, c := range cases {
+		if _, err := loadProject(&config.BTProjectCfg{Trees: c.trees}); err == nil || err.Error() != c.err {
+			t.Errorf("%s: err = %v, want %s", c.name, err, c.err)
+		}
+	}
+
+	project, err := loadProject(&config.BTProjectCfg{Trees: []config.BTTreeCfg{busyCfg("x", "a"), busyCfg("y", "b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := project.AddTree(project.GetTreeByID("x")); err == nil || err.Error() != "duplicate tree id x" {
+		t.Errorf("AddTree: err = %v", err)
+	}
+	if len(project.GetTrees()) != 2 || project.GetTree("b") != project.GetTreeByID("y") {
+		t.Errorf("trees = %v", project.GetTrees())
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	//0.2.0以前的文档用parameters
+	oldCfg := func(id string) config.BTTreeCfg {
+		return config.BTTreeCfg{ID: id, Title: id, Root: "a", Nodes: map[string]config.BTNodeCfg{
+			"a": {Id: "a", Name: "Busy", Parameters: map[string]interface{}{"ticks": 2.0}},
+		}}
+	}
+	migrated := func(name string, tree *config.BTTreeCfg) {
+		t.Helper()
+		if a := tree.Nodes["a"]; tree.Version != config.LatestVersion || a.Properties["ticks"] != 2.0 || a.Parameters != nil {
+			t.Errorf("%s: version %s, node %+v", name, tree.Version, a)
+		}
+	}
+
+	project := &config.BTProjectCfg{Trees: []config.BTTreeCfg{oldCfg("x")}}
+	if _, err := loadProject(project); err != nil {
+		t.Fatal(err)
+	}
+	migrated("project", &project.Trees[0])
+	if project.Version != config.LatestVersion {
+		t.Errorf("project version = %q", project.Version)
+	}
+
+	var events []string
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Busy", func() core.IBaseNode { return &busy{events: &events} })
+	tree := oldCfg("y")
+	loader.CreateBevTreeFromConfig(&tree, maps)
+	migrated("tree", &tree)
+
+	//内联的子树也升级
+	main := config.BTTreeCfg{Version: config.LatestVersion, ID: "main", Title: "main", Root: "s", Nodes: map[string]config.BTNodeCfg{
+		"s": {Id: "s", Name: "sub", Category: "tree"},
+	}}
+	sub := oldCfg("sub")
+	bt := core.NewBeTree()
+	bt.LoadWithOptions(&main, nil, maps, core.LoadOptions{InlineSubtrees: true, Resolve: func(name string) *config.BTTreeCfg {
+		if name == "sub" {
+			return &sub
+		}
+		return nil
+	}})
+	migrated("subtree", &sub)
+	board := core.NewBlackboard()
+	if status := bt.Tick(core.NewTick(), board); status != b3.RUNNING {
+		t.Errorf("inlined subtree status = %v", status)
+	}
+}
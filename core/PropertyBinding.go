@@ -0,0 +1,420 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+/**
+ * Property binding fills the exported fields of a node from the node
+ * properties, so that a node does not have to read them one by one in
+ * `Initialize`:
+ *
+ *     type Limiter struct {
+ *       core.Decorator
+ *       MaxLoop int `b3:"maxLoop,required,min=1"`
+ *     }
+ *
+ * The tag holds the property name followed by options:
+ *
+ * - **required** the property must be present.
+ * - **min=N**, **max=N** bounds of a numeric property.
+ * - **default=V** value used when the property is missing.
+ *
+ * Supported field types are strings, booleans, integers, floats,
+ * `time.Duration` (a number of milliseconds or a duration string), slices
+ * of these, maps, `interface{}` and `Value` for properties that may refer
+ * to the blackboard. `BehaviorTree.Load` binds every node before calling
+ * `Initialize`.
+ *
+ * @class PropertyBinding
+**/
+
+//属性的描述,由b3标签生成
+type PropertySpec struct {
+	Name     string
+	Field    string
+	Type     string
+	Required bool
+	Default  interface{}
+	Min      *float64
+	Max      *float64
+
+	index []int
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+var valueType = reflect.TypeOf(Value{})
+
+//按类型缓存属性描述
+var propertySpecCache sync.Map
+
+//获取节点的属性描述
+func GetPropertySpecs(node interface{}) ([]PropertySpec, error) {
+	t := reflect.TypeOf(node)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	if specs, ok := propertySpecCache.Load(t); ok {
+		return specs.([]PropertySpec), nil
+	}
+	var specs []PropertySpec
+	if err := collectPropertySpecs(t, nil, &specs); err != nil {
+		return nil, err
+	}
+	propertySpecCache.Store(t, specs)
+	return specs, nil
+}
+
+func collectPropertySpecs(t reflect.Type, index []int, specs *[]PropertySpec) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		tag, ok := field.Tag.Lookup("b3")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := collectPropertySpecs(field.Type, fieldIndex, specs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("%s.%s: b3 tag on unexported field", t.Name(), field.Name)
+		}
+		spec, err := parsePropertyTag(tag, field)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", t.Name(), field.Name, err)
+		}
+		spec.index = fieldIndex
+		*specs = append(*specs, spec)
+	}
+	return nil
+}
+
+func parsePropertyTag(tag string, field reflect.StructField) (PropertySpec, error) {
+	parts := strings.Split(tag, ",")
+	spec := PropertySpec{
+		Name:  strings.TrimSpace(parts[0]),
+		Field: field.Name,
+		Type:  propertyTypeName(field.Type),
+	}
+	if spec.Name == "" {
+		spec.Name = field.Name
+	}
+	if spec.Type == "" {
+		return spec, fmt.Errorf("unsupported field type %s", field.Type)
+	}
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		key, value := opt, ""
+		if n := strings.Index(opt, "="); n >= 0 {
+			key, value = opt[:n], opt[n+1:]
+		}
+		switch key {
+		case "required":
+			spec.Required = true
+		case "min", "max":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return spec, fmt.Errorf("invalid %s %q", key, value)
+			}
+			if key == "min" {
+				spec.Min = &f
+			} else {
+				spec.Max = &f
+			}
+		case "default":
+			def, err := convertPropertyValue(value, field.Type)
+			if err != nil {
+				return spec, fmt.Errorf("invalid default %q: %v", value, err)
+			}
+			spec.Default = def.Interface()
+		case "":
+		default:
+			return spec, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return spec, nil
+}
+
+func propertyTypeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	if t == valueType {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	case reflect.Slice:
+		if propertyTypeName(t.Elem()) != "" {
+			return "array"
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && propertyTypeName(t.Elem()) != "" {
+			return "object"
+		}
+	}
+	return ""
+}
+
+//用节点配置的属性填充节点的字段
+func BindProperties(node interface{}, cfg *config.BTNodeCfg) error {
+	specs, err := GetPropertySpecs(node)
+	if err != nil || len(specs) == 0 {
+		return err
+	}
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("BindProperties: node must be a non-nil pointer")
+	}
+	v = v.Elem()
+	for _, spec := range specs {
+		field := v.FieldByIndex(spec.index)
+		raw, ok := cfg.Properties[spec.Name]
+		if !ok || raw == nil {
+			if spec.Required {
+				return fmt.Errorf("property %s is required", spec.Name)
+			}
+			if spec.Default != nil {
+				field.Set(boundValue(spec, reflect.ValueOf(spec.Default)))
+			} else {
+				field.Set(boundValue(spec, field))
+			}
+			continue
+		}
+		value, err := convertPropertyValue(raw, field.Type())
+		if err != nil {
+			return fmt.Errorf("property %s: %v", spec.Name, err)
+		}
+		if err := checkPropertyRange(spec, value); err != nil {
+			return err
+		}
+		field.Set(boundValue(spec, value))
+	}
+	return nil
+}
+
+//Value记住属性名和范围,引用在tick时检查
+func boundValue(spec PropertySpec, value reflect.Value) reflect.Value {
+	if pv, ok := value.Interface().(Value); ok {
+		pv.name, pv.min, pv.max = spec.Name, spec.Min, spec.Max
+		return reflect.ValueOf(pv)
+	}
+	return value
+}
+
+func checkPropertyRange(spec PropertySpec, value reflect.Value) error {
+	if spec.Min == nil && spec.Max == nil {
+		return nil
+	}
+	raw := value.Interface()
+	if pv, ok := raw.(Value); ok {
+		if pv.IsRef() {
+			return nil
+		}
+		raw = pv.literal
+	}
+	f, ok := propertyNumber(raw)
+	if !ok {
+		if value.Type() == valueType {
+			return fmt.Errorf("property %s must be a number, got %v", spec.Name, raw)
+		}
+		return nil
+	}
+	if spec.Min != nil && f < *spec.Min {
+		return fmt.Errorf("property %s must be >= %v, got %v", spec.Name, *spec.Min, f)
+	}
+	if spec.Max != nil && f > *spec.Max {
+		return fmt.Errorf("property %s must be <= %v, got %v", spec.Name, *spec.Max, f)
+	}
+	return nil
+}
+
+func propertyNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Type() == durationType {
+			return float64(rv.Int()) / float64(time.Millisecond), true
+		}
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func convertPropertyValue(raw interface{}, t reflect.Type) (reflect.Value, error) {
+	if t == valueType {
+		pv, err := ParseValue(raw)
+		return reflect.ValueOf(pv), err
+	}
+	if t == durationType {
+		if s, ok := raw.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				return reflect.ValueOf(d), nil
+			}
+		}
+		f, ok := propertyNumber(raw)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected milliseconds or duration, got %v", raw)
+		}
+		return reflect.ValueOf(time.Duration(f * float64(time.Millisecond))), nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return v, fmt.Errorf("expected string, got %v", raw)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		switch b := raw.(type) {
+		case bool:
+			v.SetBool(b)
+		case string:
+			pb, err := strconv.ParseBool(b)
+			if err != nil {
+				return v, fmt.Errorf("expected boolean, got %q", b)
+			}
+			v.SetBool(pb)
+		default:
+			return v, fmt.Errorf("expected boolean, got %v", raw)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := propertyNumber(raw)
+		if !ok || f != float64(int64(f)) {
+			return v, fmt.Errorf("expected integer, got %v", raw)
+		}
+		if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+			if f < 0 || v.OverflowUint(uint64(f)) {
+				return v, fmt.Errorf("%v out of range", raw)
+			}
+			v.SetUint(uint64(f))
+		} else {
+			if v.OverflowInt(int64(f)) {
+				return v, fmt.Errorf("%v out of range", raw)
+			}
+			v.SetInt(int64(f))
+		}
+	case reflect.Float32, reflect.Float64:
+		f, ok := propertyNumber(raw)
+		if !ok {
+			return v, fmt.Errorf("expected number, got %v", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		if raw != nil {
+			v.Set(reflect.ValueOf(raw))
+		}
+	case reflect.Slice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return v, fmt.Errorf("expected array, got %v", raw)
+		}
+		v = reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			ev, err := convertPropertyValue(item, t.Elem())
+			if err != nil {
+				return v, fmt.Errorf("[%d]: %v", i, err)
+			}
+			v.Index(i).Set(ev)
+		}
+	case reflect.Map:
+		items, ok := raw.(map[string]interface{})
+		if !ok {
+			return v, fmt.Errorf("expected object, got %v", raw)
+		}
+		v = reflect.MakeMapWithSize(t, len(items))
+		for k, item := range items {
+			ev, err := convertPropertyValue(item, t.Elem())
+			if err != nil {
+				return v, fmt.Errorf("%s: %v", k, err)
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+		}
+	default:
+		return v, fmt.Errorf("unsupported field type %s", t)
+	}
+	return v, nil
+}
+
+//生成编辑器custom_nodes格式的节点描述
+func ExportCustomNode(name string, node IBaseNode) (config.BTCustomNodeCfg, error) {
+	node.Ctor()
+	cfg := config.BTCustomNodeCfg{
+		Version:    config.CustomNodeVersion,
+		Scope:      "node",
+		Name:       name,
+		Category:   node.GetCategory(),
+		Title:      name,
+		Properties: make(map[string]interface{}),
+	}
+	specs, err := GetPropertySpecs(node)
+	if err != nil {
+		return cfg, err
+	}
+	for _, spec := range specs {
+		value := spec.Default
+		if value == nil {
+			value = defaultPropertyValue(spec.Type)
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = float64(d) / float64(time.Millisecond)
+		}
+		if pv, ok := value.(Value); ok {
+			value = pv.literal
+		}
+		cfg.Properties[spec.Name] = value
+	}
+	return cfg, nil
+}
+
+func defaultPropertyValue(typ string) interface{} {
+	switch typ {
+	case "string":
+		return ""
+	case "boolean":
+		return false
+	case "number", "duration":
+		return 0
+	case "array":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	}
+	return nil
+}
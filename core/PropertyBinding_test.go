@@ -0,0 +1,157 @@
+package core_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+type boundNode struct {
+	core.Action
+	Count   int               `b3:"count,min=1,max=10,default=3"`
+	Name    string            `b3:"name,required"`
+	Ratio   float64           `b3:"ratio"`
+	Enabled bool              `b3:"enabled,default=true"`
+	Timeout time.Duration     `b3:"timeout,default=1500"`
+	Small   uint8             `b3:"small"`
+	Tags    []string          `b3:"tags"`
+	Weights map[string]int    `b3:"weights"`
+	Any     interface{}       `b3:"any"`
+	Target  core.Value        `b3:"target,min=0"`
+	Skipped string            `b3:"-"`
+	Plain   map[string]string // not bound
+}
+
+func bind(props map[string]interface{}) (*boundNode, error) {
+	node := &boundNode{}
+	err := core.BindProperties(node, &config.BTNodeCfg{Properties: props})
+	return node, err
+}
+
+func TestBindProperties(t *testing.T) {
+	node, err := bind(map[string]interface{}{
+		"count":   5.0,
+		"name":    "guard",
+		"ratio":   0.5,
+		"enabled": "false",
+		"timeout": "2s",
+		"small":   200.0,
+		"tags":    []interface{}{"a", "b"},
+		"weights": map[string]interface{}{"x": 1.0, "y": 2.0},
+		"any":     []interface{}{1.0},
+		"target":  "$enemy",
+		"Skipped": "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := boundNode{
+		Count: 5, Name: "guard", Ratio: 0.5, Enabled: false, Timeout: 2 * time.Second, Small: 200,
+		Tags: []string{"a", "b"}, Weights: map[string]int{"x": 1, "y": 2}, Any: []interface{}{1.0},
+	}
+	got := *node
+	got.Target = core.Value{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bound = %+v, want %+v", got, want)
+	}
+	if !node.Target.IsRef() {
+		t.Errorf("target %+v is not a blackboard reference", node.Target)
+	}
+}
+
+func TestBindDefaults(t *testing.T) {
+	node, err := bind(map[string]interface{}{"name": "x", "count": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Count != 3 || !node.Enabled || node.Timeout != 1500*time.Millisecond || node.Ratio != 0 || node.Tags != nil {
+		t.Errorf("defaults = %+v", node)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	cases := []struct {
+		props map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{}, "property name is required"},
+		{map[string]interface{}{"name": 1.0}, "property name: expected string, got 1"},
+		{map[string]interface{}{"name": "x", "count": 0.0}, "property count must be >= 1, got 0"},
+		{map[string]interface{}{"name": "x", "count": 11.0}, "property count must be <= 10, got 11"},
+		{map[string]interface{}{"name": "x", "count": 1.5}, "property count: expected integer, got 1.5"},
+		{map[string]interface{}{"name": "x", "count": "many"}, "property count: expected integer, got many"},
+		{map[string]interface{}{"name": "x", "small": 256.0}, "property small: 256 out of range"},
+		{map[string]interface{}{"name": "x", "small": -1.0}, "property small: -1 out of range"},
+		{map[string]interface{}{"name": "x", "enabled": "maybe"}, `property enabled: expected boolean, got "maybe"`},
+		{map[string]interface{}{"name": "x", "timeout": true}, "property timeout: expected milliseconds or duration, got true"},
+		{map[string]interface{}{"name": "x", "tags": "a"}, "property tags: expected array, got a"},
+		{map[string]interface{}{"name": "x", "tags": []interface{}{"a", 2.0}}, "property tags: [1]: expected string, got 2"},
+		{map[string]interface{}{"name": "x", "weights": map[string]interface{}{"x": "one"}}, "property weights: x: expected integer, got one"},
+		{map[string]interface{}{"name": "x", "target": -1.0}, "property target must be >= 0, got -1"},
+		{map[string]interface{}{"name": "x", "target": "far"}, "property target must be a number, got far"},
+	}
+	for _, c := range cases {
+		_, err := bind(c.props)
+		if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+			t.Errorf("%v: err = %v, want %s", c.props, err, c.err)
+		}
+	}
+}
+
+func TestBindUnknownProperties(t *testing.T) {
+	//properties without a field are left to the node, e.g. editor metadata
+	props := map[string]interface{}{"name": "x", "comment": "from the editor"}
+	node, err := bind(props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "x" || props["comment"] != "from the editor" {
+		t.Errorf("bound = %+v, properties = %v", node, props)
+	}
+}
+
+func TestPropertySpecErrors(t *testing.T) {
+	type unknownOption struct {
+		A int `b3:"a,positive"`
+	}
+	type badDefault struct {
+		A int `b3:"a,default=x"`
+	}
+	type badType struct {
+		A chan int `b3:"a"`
+	}
+	type unexported struct {
+		a int `b3:"a"`
+	}
+	cases := []struct {
+		node interface{}
+		err  string
+	}{
+		{&unknownOption{}, `unknownOption.A: unknown option "positive"`},
+		{&badDefault{}, `badDefault.A: invalid default "x": expected integer, got x`},
+		{&badType{}, "badType.A: unsupported field type chan int"},
+		{&unexported{}, "unexported.a: b3 tag on unexported field"},
+	}
+	for _, c := range cases {
+		if _, err := core.GetPropertySpecs(c.node); err == nil || err.Error() != c.err {
+			t.Errorf("%T: err = %v, want %s", c.node, err, c.err)
+		}
+	}
+}
+
+func TestExportCustomNode(t *testing.T) {
+	node, err := core.ExportCustomNode("Bound", &boundNode{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Category != "action" || node.Properties["count"] != 3 || node.Properties["timeout"] != 1500.0 || node.Properties["name"] != "" {
+		t.Errorf("custom node = %+v", node)
+	}
+	if _, ok := node.Properties["Skipped"]; ok {
+		t.Error("exported a field tagged b3:\"-\"")
+	}
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+ * A property value that is either a literal or a reference to a blackboard
+ * key, resolved every time it is read during a tick.
+ *
+ * A property is a reference when it is written as `"$key"` (global memory)
+ * or as an object `{"ref": "key", "scope": "tree"}`, where scope is
+ * `global` or `tree`. A string starting with `$$` is the literal string
+ * without the first `$`.
+ *
+ * Nodes declare such properties as `Value` fields with a b3 tag:
+ *
+ *     type Wait struct {
+ *       core.Action
+ *       Milliseconds core.Value `b3:"milliseconds,required"`
+ *     }
+ *
+ *     wait := w.Milliseconds.Int64(tick)
+ *
+ * `Int`, `Float64` and the other getters read a missing key as the zero
+ * value. `Number` and `Integer` return an error instead, and also when
+ * the value is out of the `min` and `max` of the b3 tag, which can only be
+ * checked at tick time for a reference:
+ *
+ *     maxLoop, err := r.MaxLoop.Integer(tick)
+ *     if err != nil {
+ *       return b3.ERROR
+ *     }
+ *
+ * @class Value
+**/
+type Value struct {
+	literal interface{}
+	ref     string
+	scope   string
+
+	//绑定时b3标签的属性名和范围
+	name string
+	min  *float64
+	max  *float64
+}
+
+const (
+	//全局黑板
+	ScopeGlobal = "global"
+	//树的黑板
+	ScopeTree = "tree"
+)
+
+//从属性的原始值创建
+func ParseValue(raw interface{}) (Value, error) {
+	switch tv := raw.(type) {
+	case string:
+		if strings.HasPrefix(tv, "$$") {
+			return Value{literal: tv[1:]}, nil
+		}
+		if strings.HasPrefix(tv, "$") && len(tv) > 1 {
+			return Value{ref: tv[1:], scope: ScopeGlobal}, nil
+		}
+	case map[string]interface{}:
+		ref, ok := tv["ref"].(string)
+		if !ok || ref == "" {
+			break
+		}
+		scope := ScopeGlobal
+		if s, ok := tv["scope"]; ok {
+			scope, _ = s.(string)
+		}
+		if scope != ScopeGlobal && scope != ScopeTree {
+			return Value{}, fmt.Errorf("unknown blackboard scope %v", tv["scope"])
+		}
+		return Value{ref: ref, scope: scope}, nil
+	case Value:
+		return tv, nil
+	}
+	return Value{literal: raw}, nil
+}
+
+//字面值
+func LiteralValue(v interface{}) Value {
+	return Value{literal: v}
+}
+
+//黑板引用
+func RefValue(key, scope string) Value {
+	if scope == "" {
+		scope = ScopeGlobal
+	}
+	return Value{ref: key, scope: scope}
+}
+
+func (v Value) IsRef() bool {
+	return v.ref != ""
+}
+
+func (v Value) Ref() (key, scope string) {
+	return v.ref, v.scope
+}
+
+//读取值,引用从tick的黑板里解析
+func (v Value) Get(tick Ticker) interface{} {
+	if v.ref == "" {
+		return v.literal
+	}
+	if v.scope == ScopeTree {
+		return tick.Blackboard().Get(v.ref, tick.GetTree().GetID(), "")
+	}
+	return tick.Blackboard().Get(v.ref, "", "")
+}
+
+//读取数值,引用的键不存在、不是数字或超出b3标签的范围时返回错误
+func (v Value) Number(tick Ticker) (float64, error) {
+	raw := v.Get(tick)
+	if raw == nil {
+		if v.ref != "" {
+			return 0, fmt.Errorf("property %s: blackboard key %s is not set", v.name, v.ref)
+		}
+		return 0, fmt.Errorf("property %s is not set", v.name)
+	}
+	f, ok := propertyNumber(raw)
+	if !ok {
+		return 0, fmt.Errorf("property %s must be a number, got %v", v.name, raw)
+	}
+	if v.min != nil && f < *v.min {
+		return f, fmt.Errorf("property %s must be >= %v, got %v", v.name, *v.min, f)
+	}
+	if v.max != nil && f > *v.max {
+		return f, fmt.Errorf("property %s must be <= %v, got %v", v.name, *v.max, f)
+	}
+	return f, nil
+}
+
+//读取整数,同Number,不是整数时返回错误
+func (v Value) Integer(tick Ticker) (int, error) {
+	f, err := v.Number(tick)
+	if err != nil {
+		return int(f), err
+	}
+	if f != float64(int(f)) {
+		return int(f), fmt.Errorf("property %s must be an integer, got %v", v.name, f)
+	}
+	return int(f), nil
+}
+
+func (v Value) Float64(tick Ticker) float64 {
+	f, _ := propertyNumber(v.Get(tick))
+	return f
+}
+
+func (v Value) Int(tick Ticker) int {
+	return int(v.Float64(tick))
+}
+
+func (v Value) Int64(tick Ticker) int64 {
+	return int64(v.Float64(tick))
+}
+
+func (v Value) Bool(tick Ticker) bool {
+	switch b := v.Get(tick).(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	}
+	return false
+}
+
+func (v Value) String(tick Ticker) string {
+	switch s := v.Get(tick).(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'g', -1, 64)
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+//数字按毫秒处理,字符串按time.ParseDuration处理
+func (v Value) Duration(tick Ticker) time.Duration {
+	raw := v.Get(tick)
+	switch d := raw.(type) {
+	case time.Duration:
+		return d
+	case string:
+		if pd, err := time.ParseDuration(d); err == nil {
+			return pd
+		}
+	}
+	f, _ := propertyNumber(raw)
+	return time.Duration(f * float64(time.Millisecond))
+}
@@ -0,0 +1,147 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+//在tick里读取Value
+type valueReader struct {
+	core.Action
+	Count core.Value `b3:"count,min=1,max=5"`
+	read  func(tick core.Ticker)
+}
+
+func (r *valueReader) OnTick(tick core.Ticker) b3.Status {
+	r.read(tick)
+	return b3.SUCCESS
+}
+
+//用一个节点的树在tick里执行read
+func readValues(t *testing.T, board *core.Blackboard, props map[string]interface{}, read func(node *valueReader, tick core.Ticker)) {
+	t.Helper()
+	node := &valueReader{}
+	node.Ctor()
+	if err := core.BindProperties(node, &config.BTNodeCfg{Properties: props}); err != nil {
+		t.Fatal(err)
+	}
+	node.read = func(tick core.Ticker) { read(node, tick) }
+	tree := core.NewBeTree()
+	tree.SetID("values")
+	tree.SetRoot(node)
+	tree.Tick(core.NewTick(), board)
+}
+
+func TestParseValue(t *testing.T) {
+	cases := []struct {
+		raw        interface{}
+		ref, scope string
+	}{
+		{"$hp", "hp", core.ScopeGlobal},
+		{map[string]interface{}{"ref": "hp", "scope": "tree"}, "hp", core.ScopeTree},
+		{map[string]interface{}{"ref": "hp"}, "hp", core.ScopeGlobal},
+		{"$$hp", "", ""},
+		{"$", "", ""},
+		{3.0, "", ""},
+		{map[string]interface{}{"x": 1.0}, "", ""},
+	}
+	for _, c := range cases {
+		v, err := core.ParseValue(c.raw)
+		if err != nil {
+			t.Fatalf("%v: %v", c.raw, err)
+		}
+		ref, scope := v.Ref()
+		if ref != c.ref || scope != c.scope || v.IsRef() != (c.ref != "") {
+			t.Errorf("%v: ref = %q %q, want %q %q", c.raw, ref, scope, c.ref, c.scope)
+		}
+	}
+	if _, err := core.ParseValue(map[string]interface{}{"ref": "hp", "scope": "node"}); err == nil || err.Error() != "unknown blackboard scope node" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestValueGet(t *testing.T) {
+	board := core.NewBlackboard()
+	board.SetMem("hp", 3.0)
+	board.SetMem("name", "orc")
+	board.SetMem("angry", "true")
+	board.SetMem("delay", "1.5s")
+	board.SetTree("hp", 7.0, "values")
+	readValues(t, board, nil, func(node *valueReader, tick core.Ticker) {
+		if got := core.RefValue("hp", "").Int(tick); got != 3 {
+			t.Errorf("global hp = %v", got)
+		}
+		if got := core.RefValue("hp", core.ScopeTree).Float64(tick); got != 7 {
+			t.Errorf("tree hp = %v", got)
+		}
+		if got := core.RefValue("name", "").String(tick); got != "orc" {
+			t.Errorf("name = %v", got)
+		}
+		if got := core.RefValue("hp", "").String(tick); got != "3" {
+			t.Errorf("hp as string = %v", got)
+		}
+		if !core.RefValue("angry", "").Bool(tick) {
+			t.Error("angry = false")
+		}
+		if got := core.RefValue("delay", "").Duration(tick); got != 1500*time.Millisecond {
+			t.Errorf("delay = %v", got)
+		}
+		if got := core.LiteralValue(250.0).Duration(tick); got != 250*time.Millisecond {
+			t.Errorf("literal delay = %v", got)
+		}
+		//missing keys read as zero values
+		if got := core.RefValue("missing", "").Int(tick); got != 0 {
+			t.Errorf("missing = %v", got)
+		}
+	})
+}
+
+func TestValueNumberChecksRefs(t *testing.T) {
+	cases := []struct {
+		set interface{}
+		err string
+		n   int
+	}{
+		{nil, "property count: blackboard key n is not set", 0},
+		{0.0, "property count must be >= 1, got 0", 0},
+		{6.0, "property count must be <= 5, got 6", 0},
+		{"many", "property count must be a number, got many", 0},
+		{2.5, "property count must be an integer, got 2.5", 0},
+		{4.0, "", 4},
+		{"2", "", 2},
+	}
+	for _, c := range cases {
+		board := core.NewBlackboard()
+		if c.set != nil {
+			board.SetMem("n", c.set)
+		}
+		readValues(t, board, map[string]interface{}{"count": "$n"}, func(node *valueReader, tick core.Ticker) {
+			n, err := node.Count.Integer(tick)
+			if c.err == "" {
+				if err != nil || n != c.n {
+					t.Errorf("%v: count = %v, %v, want %v", c.set, n, err, c.n)
+				}
+			} else if err == nil || !strings.HasPrefix(err.Error(), c.err) {
+				t.Errorf("%v: err = %v, want %s", c.set, err, c.err)
+			}
+		})
+	}
+}
+
+func TestValueNumberLiteral(t *testing.T) {
+	readValues(t, core.NewBlackboard(), map[string]interface{}{"count": 3.0}, func(node *valueReader, tick core.Ticker) {
+		if n, err := node.Count.Integer(tick); n != 3 || err != nil {
+			t.Errorf("count = %v, %v", n, err)
+		}
+	})
+	readValues(t, core.NewBlackboard(), nil, func(node *valueReader, tick core.Ticker) {
+		if _, err := node.Count.Number(tick); err == nil || err.Error() != "property count is not set" {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+/**
+ * Every blackboard, that is every agent, has its own random source, so that
+ * random nodes can be replayed: seed it when the agent is created and the
+ * agent makes the same choices every run.
+ *
+ *     board := core.NewBlackboard()
+ *     board.SetSeed(npcID)
+ *     ...
+ *     order := tick.Rand().Perm(n)
+ *
+ * Without a seed the source is seeded from the clock on first use. The
+ * source is safe for the goroutines of a Parallel node, but the order of
+ * the draws between them is not deterministic.
+ *
+ * @class Random
+**/
+
+//加锁的随机源,rand.NewSource不能并发使用
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	n := s.src.Int63()
+	s.mu.Unlock()
+	return n
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	s.src.Seed(seed)
+	s.mu.Unlock()
+}
+
+func newRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed)})
+}
+
+//设置随机种子,重新开始随机序列
+func (b *Blackboard) SetSeed(seed int64) {
+	b.randMu.Lock()
+	b.rand = newRand(seed)
+	b.randMu.Unlock()
+}
+
+//黑板的随机数生成器
+func (b *Blackboard) Rand() *rand.Rand {
+	b.randMu.Lock()
+	defer b.randMu.Unlock()
+	if b.rand == nil {
+		b.rand = newRand(time.Now().UnixNano())
+	}
+	return b.rand
+}
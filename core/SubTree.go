@@ -8,7 +8,8 @@ import (
 //子树，通过Name关联树ID查找
 type SubTree struct {
 	Action
-	//tree *BehaviorTree
+	//所属的工程,没有时使用SetSubTreeLoadFunc
+	project *Project
 }
 
 func (t *SubTree) Initialize(setting *config.BTNodeCfg) {
@@ -23,27 +24,39 @@ func (t *SubTree) Initialize(setting *config.BTNodeCfg) {
 **/
 func (t *SubTree) OnTick(tick Ticker) b3.Status {
 
-	//使用子树，必须先SetSubTreeLoadFunc
 	//子树可能没有加载上来，所以要延迟加载执行
-	sTree := subTreeLoadFunc(t.GetTitle())
+	sTree := t.GetSubTree()
 	if nil == sTree {
 		return b3.ERROR
 	}
 
 	tick.pushSubtreeNode(t)
-	sTree.id = t.GetID()
 	ret := sTree.GetRoot().Execute(tick)
 	tick.popSubtreeNode()
 	return ret
 }
 
+//从所属工程查找子树,不在工程里时使用SetSubTreeLoadFunc
+func (t *SubTree) GetSubTree() *BehaviorTree {
+	if t.project != nil {
+		if sTree := t.project.GetTree(t.GetName()); sTree != nil {
+			return sTree
+		}
+		return t.project.GetTree(t.GetTitle())
+	}
+	if subTreeLoadFunc == nil {
+		return nil
+	}
+	return subTreeLoadFunc(t.GetTitle())
+}
+
 func (t *SubTree) String() string {
 	return "SBT_" + t.GetTitle()
 }
 
 var subTreeLoadFunc func(string) *BehaviorTree
 
-//获取子树的方法
+//获取子树的方法,只用于不属于Project的树
 func SetSubTreeLoadFunc(f func(string) *BehaviorTree) {
 	subTreeLoadFunc = f
 }
@@ -1,10 +1,13 @@
 package core
 
+import "math/rand"
+
 type Ticker interface {
 	Initialize()
 	GetTree() *BehaviorTree
 	GetLastSubTree() *SubTree
 	Blackboard() *Blackboard
+	Rand() *rand.Rand
 	Tear(ticker Ticker)
 	TearTick() Ticker
 	_enterNode(node IBaseNode)
@@ -19,6 +22,9 @@ type Ticker interface {
 	setTree(tree *BehaviorTree)
 	setBlackboard(blackboard *Blackboard)
 	setDebug(debug interface{})
+	setNodeStates(tree *BehaviorTree, states []nodeState)
+	nodeState(tree *BehaviorTree, index int) *nodeState
+	reset()
 }
 
 /**
@@ -82,6 +88,12 @@ type Tick struct {
 	 * @readOnly
 	**/
 	_nodeCount int
+
+	/**
+	 * The node states of the compiled tree being ticked.
+	**/
+	_statesTree *BehaviorTree
+	_states     []nodeState
 }
 
 func NewTick() *Tick {
@@ -105,6 +117,17 @@ func (t *Tick) Initialize() {
 	t._openNodes = nil
 	t._openSubtreeNodes = nil
 	t._nodeCount = 0
+	t._statesTree = nil
+	t._states = nil
+}
+
+//复用tick时清空上次遍历的记录,保留已分配的空间
+func (t *Tick) reset() {
+	t._openNodes = t._openNodes[:0]
+	t._openSubtreeNodes = t._openSubtreeNodes[:0]
+	t._nodeCount = 0
+	t._statesTree = nil
+	t._states = nil
 }
 
 func (t *Tick) GetTree() *BehaviorTree {
@@ -152,11 +175,13 @@ func (t *Tick) _tickNode(node *BaseNode) {
 **/
 func (t *Tick) _closeNode(node *BaseNode) {
 	// TODO: call debug here
-	ulen := len(t._openNodes)
-	if ulen > 0 {
-		t._openNodes = t._openNodes[:ulen-1]
+	//通常是最后打开的节点,被父节点提前关闭的子节点可能不在最后
+	for i := len(t._openNodes) - 1; i >= 0; i-- {
+		if t._openNodes[i] == IBaseNode(node) {
+			t._openNodes = append(t._openNodes[:i], t._openNodes[i+1:]...)
+			break
+		}
 	}
-
 }
 
 func (t *Tick) pushSubtreeNode(node *SubTree) {
@@ -196,6 +221,11 @@ func (t *Tick) Blackboard() *Blackboard {
 	return t.blackboard
 }
 
+//黑板(agent)的随机数生成器,可以用Blackboard.SetSeed重现
+func (t *Tick) Rand() *rand.Rand {
+	return t.blackboard.Rand()
+}
+
 func (t *Tick) setTree(tree *BehaviorTree) {
 	t.tree = tree
 }
@@ -216,8 +246,22 @@ func (t *Tick) setDebug(debug interface{}) {
 	t.debug = debug
 }
 
+func (t *Tick) setNodeStates(tree *BehaviorTree, states []nodeState) {
+	t._statesTree = tree
+	t._states = states
+}
+
+func (t *Tick) nodeState(tree *BehaviorTree, index int) *nodeState {
+	if tree != t._statesTree || t._states == nil {
+		return nil
+	}
+	return &t._states[index]
+}
+
 func (t *Tick) Tear(ticker Ticker) {
 	tick := ticker.(*Tick)
+	tick._statesTree = t._statesTree
+	tick._states = t._states
 	tick.blackboard = t.blackboard
 	tick._openNodes = append(tick._openNodes, t._openNodes...)
 	tick._nodeCount = t._nodeCount
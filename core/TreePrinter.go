@@ -0,0 +1,169 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	b3 "github.com/magicsea/behavior3go"
+)
+
+/**
+ * Writes the structure of a tree, one node per line, indented by depth:
+ *
+ *     tree.Fprint(os.Stdout, blackboard)
+ *
+ * Every line holds the title, the node name when it differs from the title
+ * and the properties. With a blackboard each node is also annotated with
+ * the state of that agent: whether it is open, the status of its last tick
+ * and its node memory, such as `runningChild` or the loop counter `i`. The
+ * status is only known for compiled trees and after `SetTrackStatus(true)`:
+ *
+ *     MemSequence [open RUNNING runningChild=1]
+ *       Repeat 2x (Repeater) maxLoop=2 [SUCCESS i=2]
+ *       Wait 1000ms (Wait) milliseconds=1000 [open RUNNING startTime=1571...]
+ *
+ * Subtrees of a project are printed under their SubTree node.
+ *
+ * @method Fprint
+ * @param {io.Writer} w The writer.
+ * @param {Blackboard} [blackboard] The blackboard of the agent, may be nil.
+**/
+func (t *BehaviorTree) Fprint(w io.Writer, blackboard *Blackboard) error {
+	bw := bufio.NewWriter(w)
+	p := &treePrinter{w: bw, blackboard: blackboard, treeScope: t.id, visiting: map[*BehaviorTree]bool{t: true}}
+	if t.root != nil {
+		p.printNode(t.root, 0)
+	}
+	return bw.Flush()
+}
+
+//打印到标准输出
+func (t *BehaviorTree) Print() {
+	t.Fprint(os.Stdout, nil)
+}
+
+type treePrinter struct {
+	w          io.Writer
+	blackboard *Blackboard
+	treeScope  string
+	//正在打印的子树,防止递归
+	visiting map[*BehaviorTree]bool
+}
+
+func (p *treePrinter) printNode(node IBaseNode, depth int) {
+	var props map[string]interface{}
+	if n, ok := node.(interface {
+		GetProperties() map[string]interface{}
+	}); ok {
+		props = n.GetProperties()
+	}
+	title, rest := ExpandTitle(node.GetTitle(), props, func(v interface{}) string { return fmt.Sprint(v) })
+	line := strings.Repeat("  ", depth) + title
+	if node.GetName() != node.GetTitle() {
+		line += " (" + node.GetName() + ")"
+	}
+	for _, k := range rest {
+		line += " " + k + "=" + fmt.Sprint(props[k])
+	}
+	if state := p.nodeState(node); state != "" {
+		line += " [" + state + "]"
+	}
+	fmt.Fprintln(p.w, line)
+
+	switch tn := node.(type) {
+	case *SubTree:
+		if sub := tn.GetSubTree(); sub != nil && sub.root != nil && !p.visiting[sub] {
+			p.visiting[sub] = true
+			p.printNode(sub.root, depth+1)
+			delete(p.visiting, sub)
+		}
+		return
+	}
+	switch node.GetCategory() {
+	case b3.COMPOSITE:
+		comp := node.(IComposite)
+		for i := 0; i < comp.GetChildCount(); i++ {
+			p.printNode(comp.GetChild(i), depth+1)
+		}
+	case b3.DECORATOR:
+		if child := node.(IDecorator).GetChild(); child != nil {
+			p.printNode(child, depth+1)
+		}
+	}
+}
+
+//节点在黑板里的状态:是否打开,上次的状态和其他节点内存
+func (p *treePrinter) nodeState(node IBaseNode) string {
+	if p.blackboard == nil {
+		return ""
+	}
+	values := make(map[string]interface{})
+	if memory := p.blackboard._findNodeMemory(p.treeScope, node.GetID()); memory != nil {
+		memory.Range(func(key string, value interface{}) bool {
+			values[key] = value
+			return true
+		})
+	}
+	// compiled trees keep the open flag and the status out of the node memory
+	if owner, index := node._compiled(); owner != nil && owner.id == p.treeScope {
+		if tm, ok := p.blackboard.treeMemory.Load(p.treeScope); ok {
+			if states := tm.(*TreeMemory).states; index < len(states) && states[index].status != 0 {
+				values["isOpen"] = states[index].open
+				values["lastStatus"] = states[index].status
+			}
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	var parts []string
+	if open, _ := values["isOpen"].(bool); open {
+		parts = append(parts, "open")
+	}
+	if status, ok := values["lastStatus"].(b3.Status); ok {
+		parts = append(parts, status.String())
+	}
+	delete(values, "isOpen")
+	delete(values, "lastStatus")
+	for _, k := range sortedKeys(values) {
+		parts = append(parts, k+"="+fmt.Sprint(values[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+/**
+ * Replaces the `<key>` placeholders of a node title by the formatted
+ * property values, as the editor does, and returns the sorted keys of the
+ * other properties.
+ *
+ * @method ExpandTitle
+ * @param {String} title The node title.
+ * @param {Object} properties The node properties.
+ * @param {Function} format Formats a property value.
+ * @return {String} The title and the keys of the properties left.
+**/
+func ExpandTitle(title string, properties map[string]interface{}, format func(interface{}) string) (string, []string) {
+	var rest []string
+	for _, k := range sortedKeys(properties) {
+		placeholder := "<" + k + ">"
+		if strings.Contains(title, placeholder) && properties[k] != nil {
+			title = strings.Replace(title, placeholder, format(properties[k]), -1)
+			continue
+		}
+		rest = append(rest, k)
+	}
+	return title, rest
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
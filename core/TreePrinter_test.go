@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/magicsea/behavior3go/core"
+)
+
+const printedTree = `tree "t"
+  MemSequence @id=seq nonBlocking=true
+    Repeater "Repeat <maxLoop>x" @id=rep maxLoop=2
+      Succeeder @id=ok
+    Limiter @id=lim maxLoop=3
+      Succeeder @id=once
+    Busy "Busy <ticks> ticks" @id=busy ticks=5
+    Log @id=log info=done
+`
+
+func fprint(t *testing.T, tree *core.BehaviorTree, board *core.Blackboard) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tree.Fprint(&buf, board); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFprintStructure(t *testing.T) {
+	var events []string
+	tree := loadTextTree(t, printedTree, &events)
+	want := `MemSequence nonBlocking=true
+  Repeat 2x (Repeater)
+    Succeeder
+  Limiter maxLoop=3
+    Succeeder
+  Busy 5 ticks (Busy)
+  Log info=done
+`
+	if got := fprint(t, tree, nil); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFprintState(t *testing.T) {
+	var events []string
+	tree := loadTextTree(t, printedTree, &events)
+	tree.SetTrackStatus(true)
+	board := core.NewBlackboard()
+	tick := core.NewTick()
+	tree.Tick(tick, board)
+	tree.Tick(tick, board)
+	want := `MemSequence nonBlocking=true [open RUNNING runningChild=2]
+  Repeat 2x (Repeater) [SUCCESS i=2]
+    Succeeder [SUCCESS]
+  Limiter maxLoop=3 [SUCCESS i=1]
+    Succeeder [SUCCESS]
+  Busy 5 ticks (Busy) [open RUNNING left=3]
+  Log info=done
+`
+	if got := fprint(t, tree, board); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFprintStateWithoutStatus(t *testing.T) {
+	var events []string
+	tree := loadTextTree(t, printedTree, &events)
+	board := core.NewBlackboard()
+	tree.Tick(core.NewTick(), board)
+	//没有SetTrackStatus时不记录状态,只显示节点内存
+	want := `MemSequence nonBlocking=true [open runningChild=2]
+  Repeat 2x (Repeater) [i=2]
+    Succeeder
+  Limiter maxLoop=3 [i=1]
+    Succeeder
+  Busy 5 ticks (Busy) [open left=4]
+  Log info=done
+`
+	if got := fprint(t, tree, board); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	//编译后的树总是有状态
+	compiled := loadTextTree(t, printedTree, &events)
+	compiled.Compile()
+	board = core.NewBlackboard()
+	compiled.Tick(core.NewTick(), board)
+	want = `MemSequence nonBlocking=true [open RUNNING runningChild=2]
+  Repeat 2x (Repeater) [SUCCESS i=2]
+    Succeeder [SUCCESS]
+  Limiter maxLoop=3 [SUCCESS i=1]
+    Succeeder [SUCCESS]
+  Busy 5 ticks (Busy) [open RUNNING left=4]
+  Log info=done
+`
+	if got := fprint(t, compiled, board); got != want {
+		t.Errorf("compiled: got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package decorators_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+	"github.com/magicsea/behavior3go/loader"
+)
+
+//每次tick计数并成功
+type counter struct {
+	core.Action
+	ticks *int
+}
+
+func (c *counter) OnTick(tick core.Ticker) b3.Status {
+	*c.ticks++
+	return b3.SUCCESS
+}
+
+//decorator节点下面一个counter
+func decoratorTree(t *testing.T, name, props string) (*core.BehaviorTree, *int) {
+	t.Helper()
+	var cfg config.BTTreeCfg
+	js := `{"id":"t","title":"t","root":"d","nodes":{
+		"d":{"id":"d","name":"` + name + `","category":"decorator","child":"c","properties":` + props + `},
+		"c":{"id":"c","name":"Count","category":"action"}}}`
+	if err := json.Unmarshal([]byte(js), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	ticks := new(int)
+	maps := core.NewRegisterStructMaps()
+	maps.Register("Count", func() core.IBaseNode { return &counter{ticks: ticks} })
+	return loader.CreateBevTreeFromConfig(&cfg, maps), ticks
+}
+
+func TestRepeaterRefMaxLoop(t *testing.T) {
+	//the child always succeeds: Repeater and RepeatUntilFailure run it
+	//maxLoop times, RepeatUntilSuccess stops after the first time
+	cases := []struct {
+		name   string
+		n      interface{}
+		status b3.Status
+		ticks  int
+	}{
+		{"Repeater", nil, b3.ERROR, 0},
+		{"Repeater", 0.0, b3.ERROR, 0},
+		{"Repeater", 3.0, b3.SUCCESS, 3},
+		{"RepeatUntilFailure", nil, b3.ERROR, 0},
+		{"RepeatUntilFailure", -1.0, b3.ERROR, 0},
+		{"RepeatUntilFailure", 2.0, b3.SUCCESS, 2},
+		{"RepeatUntilSuccess", nil, b3.ERROR, 0},
+		{"RepeatUntilSuccess", "x", b3.ERROR, 0},
+		{"RepeatUntilSuccess", 3.0, b3.SUCCESS, 1},
+	}
+	for _, c := range cases {
+		tree, ticks := decoratorTree(t, c.name, `{"maxLoop":"$n"}`)
+		board := core.NewBlackboard()
+		if c.n != nil {
+			board.SetMem("n", c.n)
+		}
+		if status := tree.Tick(core.NewTick(), board); status != c.status || *ticks != c.ticks {
+			t.Errorf("%s maxLoop=%v: status %v after %d ticks, want %v after %d", c.name, c.n, status, *ticks, c.status, c.ticks)
+		}
+	}
+}
+
+func TestLimiterRefMaxLoop(t *testing.T) {
+	tree, ticks := decoratorTree(t, "Limiter", `{"maxLoop":{"ref":"n","scope":"tree"}}`)
+	board := core.NewBlackboard()
+	tick := core.NewTick()
+	if status := tree.Tick(tick, board); status != b3.ERROR || *ticks != 0 {
+		t.Fatalf("missing maxLoop: status %v after %d ticks", status, *ticks)
+	}
+	board.SetTree("n", 2.0, "t")
+	want := []b3.Status{b3.SUCCESS, b3.SUCCESS, b3.FAILURE}
+	for i, w := range want {
+		if status := tree.Tick(tick, board); status != w {
+			t.Errorf("tick %d: status %v, want %v", i, status, w)
+		}
+	}
+	if *ticks != 2 {
+		t.Errorf("child ticked %d times, want 2", *ticks)
+	}
+}
+
+func TestMaxTimeRefMissing(t *testing.T) {
+	tree, ticks := decoratorTree(t, "MaxTime", `{"maxTime":"$limit"}`)
+	if status := tree.Tick(core.NewTick(), core.NewBlackboard()); status != b3.ERROR || *ticks != 0 {
+		t.Errorf("status %v after %d ticks, want ERROR", status, *ticks)
+	}
+}
@@ -2,7 +2,6 @@ package decorators
 
 import (
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
@@ -17,27 +16,13 @@ import (
 **/
 type Limiter struct {
 	core.Decorator
-	maxLoop int
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (l *Limiter) Initialize(setting *config.BTNodeCfg) {
-	l.Decorator.Initialize(setting)
-	l.maxLoop = setting.GetPropertyAsInt("maxLoop")
-	if l.maxLoop < 1 {
-		panic("maxLoop parameter in MaxTime decorator is an obligatory parameter")
-	}
+	/**
+	 * Maximum number of times the child can be called.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} maxLoop
+	**/
+	MaxLoop core.Value `b3:"maxLoop,required,min=1"`
 }
 
 /**
@@ -50,8 +35,12 @@ func (l *Limiter) OnTick(tick core.Ticker) b3.Status {
 	if l.GetChild() == nil {
 		return b3.ERROR
 	}
+	maxLoop, err := l.MaxLoop.Integer(tick)
+	if err != nil {
+		return b3.ERROR
+	}
 	var i = tick.Blackboard().GetInt("i", tick.GetTree().GetID(), l.GetID())
-	if i < l.maxLoop {
+	if i < maxLoop {
 		var status = l.GetChild().Execute(tick)
 		if status == b3.SUCCESS || status == b3.FAILURE {
 			tick.Blackboard().Set("i", i+1, tick.GetTree().GetID(), l.GetID())
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
@@ -20,27 +19,13 @@ import (
 **/
 type MaxTime struct {
 	core.Decorator
-	maxTime int64
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (t *MaxTime) Initialize(setting *config.BTNodeCfg) {
-	t.Decorator.Initialize(setting)
-	t.maxTime = setting.GetPropertyAsInt64("maxTime")
-	if t.maxTime < 1 {
-		panic("maxTime parameter in Limiter decorator is an obligatory parameter")
-	}
+	/**
+	 * Maximum time, in milliseconds, a child can execute.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} maxTime
+	**/
+	MaxTime core.Value `b3:"maxTime,required,min=1"`
 }
 
 /**
@@ -63,10 +48,14 @@ func (t *MaxTime) OnTick(tick core.Ticker) b3.Status {
 	if t.GetChild() == nil {
 		return b3.ERROR
 	}
+	maxTime, err := t.MaxTime.Number(tick)
+	if err != nil {
+		return b3.ERROR
+	}
 	var currTime = time.Now().UnixNano() / 1000000
 	var startTime int64 = tick.Blackboard().GetInt64("startTime", tick.GetTree().GetID(), t.GetID())
 	var status = t.GetChild().Execute(tick)
-	if currTime-startTime > t.maxTime {
+	if float64(currTime-startTime) > maxTime {
 		return b3.FAILURE
 	}
 	return status
@@ -2,7 +2,6 @@ package decorators
 
 import (
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
@@ -18,27 +17,13 @@ import (
 **/
 type RepeatUntilFailure struct {
 	core.Decorator
-	maxLoop int
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (f *RepeatUntilFailure) Initialize(setting *config.BTNodeCfg) {
-	f.Decorator.Initialize(setting)
-	f.maxLoop = setting.GetPropertyAsInt("maxLoop")
-	if f.maxLoop < 1 {
-		panic("maxLoop parameter in MaxTime decorator is an obligatory parameter")
-	}
+	/**
+	 * Maximum number of repetitions.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} maxLoop
+	**/
+	MaxLoop core.Value `b3:"maxLoop,required,min=1"`
 }
 
 /**
@@ -60,9 +45,13 @@ func (f *RepeatUntilFailure) OnTick(tick core.Ticker) b3.Status {
 	if f.GetChild() == nil {
 		return b3.ERROR
 	}
+	maxLoop, err := f.MaxLoop.Integer(tick)
+	if err != nil {
+		return b3.ERROR
+	}
 	var i = tick.Blackboard().GetInt("i", tick.GetTree().GetID(), f.GetID())
 	var status = b3.ERROR
-	for f.maxLoop < 0 || i < f.maxLoop {
+	for maxLoop < 0 || i < maxLoop {
 		status = f.GetChild().Execute(tick)
 		if status == b3.SUCCESS {
 			i++
@@ -2,7 +2,6 @@ package decorators
 
 import (
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
@@ -18,27 +17,13 @@ import (
 **/
 type RepeatUntilSuccess struct {
 	core.Decorator
-	maxLoop int
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (s *RepeatUntilSuccess) Initialize(setting *config.BTNodeCfg) {
-	s.Decorator.Initialize(setting)
-	s.maxLoop = setting.GetPropertyAsInt("maxLoop")
-	if s.maxLoop < 1 {
-		panic("maxLoop parameter in MaxTime decorator is an obligatory parameter")
-	}
+	/**
+	 * Maximum number of repetitions.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} maxLoop
+	**/
+	MaxLoop core.Value `b3:"maxLoop,required,min=1"`
 }
 
 /**
@@ -60,9 +45,13 @@ func (s *RepeatUntilSuccess) OnTick(tick core.Ticker) b3.Status {
 	if s.GetChild() == nil {
 		return b3.ERROR
 	}
+	maxLoop, err := s.MaxLoop.Integer(tick)
+	if err != nil {
+		return b3.ERROR
+	}
 	var i = tick.Blackboard().GetInt("i", tick.GetTree().GetID(), s.GetID())
 	var status = b3.ERROR
-	for s.maxLoop < 0 || i < s.maxLoop {
+	for maxLoop < 0 || i < maxLoop {
 		status = s.GetChild().Execute(tick)
 		if status == b3.FAILURE {
 			i++
@@ -2,7 +2,6 @@ package decorators
 
 import (
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
@@ -17,27 +16,13 @@ import (
 **/
 type Repeater struct {
 	core.Decorator
-	maxLoop int
-}
 
-/**
- * Initialization method.
- *
- * Settings parameters:
- *
- * - **milliseconds** (*Integer*) Maximum time, in milliseconds, a child
- *                                can execute.
- *
- * @method Initialize
- * @param {Object} settings Object with parameters.
- * @construCtor
-**/
-func (r *Repeater) Initialize(setting *config.BTNodeCfg) {
-	r.Decorator.Initialize(setting)
-	r.maxLoop = setting.GetPropertyAsInt("maxLoop")
-	if r.maxLoop < 1 {
-		panic("maxLoop parameter in MaxTime decorator is an obligatory parameter")
-	}
+	/**
+	 * Maximum number of repetitions.
+	 * Can be a blackboard reference such as `"$key"`.
+	 * @property {Integer} maxLoop
+	**/
+	MaxLoop core.Value `b3:"maxLoop,required,min=1"`
 }
 
 /**
@@ -59,9 +44,13 @@ func (r *Repeater) OnTick(tick core.Ticker) b3.Status {
 	if r.GetChild() == nil {
 		return b3.ERROR
 	}
+	maxLoop, err := r.MaxLoop.Integer(tick)
+	if err != nil {
+		return b3.ERROR
+	}
 	var i = tick.Blackboard().GetInt("i", tick.GetTree().GetID(), r.GetID())
 	var status = b3.SUCCESS
-	for r.maxLoop < 0 || i < r.maxLoop {
+	for maxLoop < 0 || i < maxLoop {
 		status = r.GetChild().Execute(tick)
 		if status == b3.SUCCESS {
 			i++
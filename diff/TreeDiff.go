@@ -0,0 +1,387 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+/**
+ * Structural comparison of two versions of a tree or a project.
+ *
+ * Nodes are matched by ID. Nodes whose IDs changed (for example because the
+ * editor regenerated them) are matched by position instead: the children at
+ * the same index of two matched nodes are matched with each other.
+ *
+ * @module diff
+**/
+
+type ChangeKind string
+
+const (
+	TreeAdded         ChangeKind = "tree added"
+	TreeRemoved       ChangeKind = "tree removed"
+	TreeChanged       ChangeKind = "tree changed"
+	NodeAdded         ChangeKind = "added"
+	NodeRemoved       ChangeKind = "removed"
+	NodeMoved         ChangeKind = "moved"
+	TypeChanged       ChangeKind = "type changed"
+	TitleChanged      ChangeKind = "title changed"
+	PropertyChanged   ChangeKind = "property changed"
+	ChildrenReordered ChangeKind = "children reordered"
+)
+
+//一处改动
+type Change struct {
+	Kind ChangeKind
+	//树的标题
+	Tree string
+	//节点ID,新增和改动使用新版本的ID,删除使用旧版本的ID
+	NodeID string
+	//节点在树里的路径
+	Path string
+	//改动的属性名,树的改动为title,description或属性名
+	Property string
+	Old      interface{}
+	New      interface{}
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	b.WriteString("[" + c.Tree + "] ")
+	b.WriteString(string(c.Kind))
+	if c.Path != "" {
+		b.WriteString(" " + c.Path)
+	}
+	switch c.Kind {
+	case PropertyChanged, TreeChanged:
+		fmt.Fprintf(&b, ": %s %s -> %s", c.Property, formatValue(c.Old), formatValue(c.New))
+	case TypeChanged, TitleChanged, ChildrenReordered:
+		fmt.Fprintf(&b, ": %s -> %s", formatValue(c.Old), formatValue(c.New))
+	case NodeMoved:
+		fmt.Fprintf(&b, ": from %s", formatValue(c.Old))
+	}
+	return b.String()
+}
+
+func formatValue(v interface{}) string {
+	switch tv := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return fmt.Sprintf("%q", tv)
+	case []string:
+		return "[" + strings.Join(tv, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+//比较两个工程,树按ID匹配,找不到再按标题匹配
+func DiffProjects(a, b *config.BTProjectCfg) []Change {
+	var changes []Change
+	matched := make(map[int]int)
+	used := make(map[int]bool)
+	for i := range a.Trees {
+		for j := range b.Trees {
+			if !used[j] && a.Trees[i].ID == b.Trees[j].ID {
+				matched[i], used[j] = j, true
+				break
+			}
+		}
+	}
+	for i := range a.Trees {
+		if _, ok := matched[i]; ok {
+			continue
+		}
+		for j := range b.Trees {
+			if !used[j] && a.Trees[i].Title == b.Trees[j].Title {
+				matched[i], used[j] = j, true
+				break
+			}
+		}
+	}
+
+	for j := range b.Trees {
+		found := false
+		for i := range a.Trees {
+			if mj, ok := matched[i]; ok && mj == j {
+				changes = append(changes, DiffTrees(&a.Trees[i], &b.Trees[j])...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			changes = append(changes, Change{Kind: TreeAdded, Tree: b.Trees[j].Title})
+		}
+	}
+	for i := range a.Trees {
+		if _, ok := matched[i]; !ok {
+			changes = append(changes, Change{Kind: TreeRemoved, Tree: a.Trees[i].Title})
+		}
+	}
+	return changes
+}
+
+type treeDiff struct {
+	a, b    *config.BTTreeCfg
+	aToB    map[string]string
+	bToA    map[string]string
+	aParent map[string]string
+	bParent map[string]string
+	aPath   map[string]string
+	bPath   map[string]string
+	aOrder  []string
+	changes []Change
+}
+
+//比较同一棵树的两个版本
+func DiffTrees(a, b *config.BTTreeCfg) []Change {
+	d := &treeDiff{
+		a:       a,
+		b:       b,
+		aToB:    make(map[string]string),
+		bToA:    make(map[string]string),
+		aParent: make(map[string]string),
+		bParent: make(map[string]string),
+		aPath:   make(map[string]string),
+		bPath:   make(map[string]string),
+	}
+	d.diffTreeAttributes()
+	d.aOrder = indexTree(a, d.aParent, d.aPath)
+	indexTree(b, d.bParent, d.bPath)
+	d.match()
+	d.walk(b.Root, make(map[string]bool))
+
+	// removed nodes, in the order of the old tree, then the detached ones
+	var removed, detached []string
+	for _, id := range d.aOrder {
+		if _, ok := d.aToB[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	for id := range a.Nodes {
+		if _, ok := d.aPath[id]; !ok {
+			if _, ok := d.aToB[id]; !ok {
+				detached = append(detached, id)
+			}
+		}
+	}
+	sort.Strings(detached)
+	for _, id := range append(removed, detached...) {
+		d.add(Change{Kind: NodeRemoved, NodeID: id, Path: pathOr(d.aPath, id, a)})
+	}
+	return d.changes
+}
+
+func (d *treeDiff) add(c Change) {
+	c.Tree = d.b.Title
+	if c.Tree == "" {
+		c.Tree = d.a.Title
+	}
+	d.changes = append(d.changes, c)
+}
+
+func (d *treeDiff) diffTreeAttributes() {
+	if d.a.Title != d.b.Title {
+		d.add(Change{Kind: TreeChanged, Property: "title", Old: d.a.Title, New: d.b.Title})
+	}
+	if d.a.Description != d.b.Description {
+		d.add(Change{Kind: TreeChanged, Property: "description", Old: d.a.Description, New: d.b.Description})
+	}
+	for _, c := range diffProperties(d.a.Properties, d.b.Properties) {
+		c.Kind = TreeChanged
+		d.add(c)
+	}
+}
+
+//先按ID匹配,再按位置匹配
+func (d *treeDiff) match() {
+	for id := range d.a.Nodes {
+		if _, ok := d.b.Nodes[id]; ok {
+			d.aToB[id], d.bToA[id] = id, id
+		}
+	}
+	d.pair(d.a.Root, d.b.Root)
+
+	// children at the same index of matched nodes are matched by position
+	queue := [][2]string{{d.a.Root, d.b.Root}}
+	for _, id := range d.aOrder {
+		if m, ok := d.aToB[id]; ok {
+			queue = append(queue, [2]string{id, m})
+		}
+	}
+	visited := make(map[string]bool)
+	for len(queue) > 0 {
+		aID, bID := queue[0][0], queue[0][1]
+		queue = queue[1:]
+		if visited[aID] || d.aToB[aID] != bID {
+			continue
+		}
+		visited[aID] = true
+		aChildren, bChildren := children(d.a.Nodes[aID]), children(d.b.Nodes[bID])
+		for i := 0; i < len(aChildren) && i < len(bChildren); i++ {
+			d.pair(aChildren[i], bChildren[i])
+		}
+		for _, c := range aChildren {
+			if m, ok := d.aToB[c]; ok {
+				queue = append(queue, [2]string{c, m})
+			}
+		}
+	}
+}
+
+//两个都没有匹配时按位置匹配
+func (d *treeDiff) pair(aID, bID string) {
+	if _, ok := d.a.Nodes[aID]; !ok {
+		return
+	}
+	if _, ok := d.b.Nodes[bID]; !ok {
+		return
+	}
+	_, aMatched := d.aToB[aID]
+	_, bMatched := d.bToA[bID]
+	if !aMatched && !bMatched {
+		d.aToB[aID], d.bToA[bID] = bID, aID
+	}
+}
+
+func (d *treeDiff) walk(bID string, visited map[string]bool) {
+	node, ok := d.b.Nodes[bID]
+	if !ok || visited[bID] {
+		return
+	}
+	visited[bID] = true
+	path := d.bPath[bID]
+	aID, matched := d.bToA[bID]
+	if !matched {
+		d.add(Change{Kind: NodeAdded, NodeID: bID, Path: path, New: node.Name})
+	} else {
+		d.diffNode(aID, bID, path)
+	}
+	for _, c := range children(node) {
+		d.walk(c, visited)
+	}
+}
+
+func (d *treeDiff) diffNode(aID, bID, path string) {
+	old, cur := d.a.Nodes[aID], d.b.Nodes[bID]
+	if old.Name != cur.Name || old.Category != cur.Category && old.Category != "" && cur.Category != "" {
+		d.add(Change{Kind: TypeChanged, NodeID: bID, Path: path, Old: nodeType(old), New: nodeType(cur)})
+	}
+	if old.Title != cur.Title {
+		d.add(Change{Kind: TitleChanged, NodeID: bID, Path: path, Old: old.Title, New: cur.Title})
+	}
+	for _, c := range diffProperties(old.Properties, cur.Properties) {
+		c.NodeID, c.Path = bID, path
+		d.add(c)
+	}
+
+	// moved when the parent is not the match of the old parent
+	aParent, aHasParent := d.aParent[aID]
+	bParent, bHasParent := d.bParent[bID]
+	if aHasParent && bHasParent && d.aToB[aParent] != bParent || aHasParent != bHasParent {
+		d.add(Change{Kind: NodeMoved, NodeID: bID, Path: path, Old: pathOr(d.aPath, aID, d.a)})
+	}
+
+	// order of the children that stayed under this node
+	var oldOrder, newOrder []string
+	for _, c := range children(old) {
+		if m, ok := d.aToB[c]; ok && d.bParent[m] == bID {
+			oldOrder = append(oldOrder, m)
+		}
+	}
+	for _, c := range children(cur) {
+		if m, ok := d.bToA[c]; ok && d.aParent[m] == aID {
+			newOrder = append(newOrder, c)
+		}
+	}
+	if !reflect.DeepEqual(oldOrder, newOrder) {
+		d.add(Change{Kind: ChildrenReordered, NodeID: bID, Path: path, Old: d.titles(oldOrder), New: d.titles(newOrder)})
+	}
+}
+
+func (d *treeDiff) titles(ids []string) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = d.b.Nodes[id].Title
+	}
+	return result
+}
+
+func diffProperties(a, b map[string]interface{}) []Change {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	var changes []Change
+	for _, k := range sorted {
+		av, aok := a[k]
+		bv, bok := b[k]
+		if aok == bok && reflect.DeepEqual(av, bv) {
+			continue
+		}
+		changes = append(changes, Change{Kind: PropertyChanged, Property: k, Old: av, New: bv})
+	}
+	return changes
+}
+
+//记录每个节点的父节点和路径,返回深度优先的顺序
+func indexTree(tree *config.BTTreeCfg, parents, paths map[string]string) []string {
+	var order []string
+	var walk func(id, path string)
+	walk = func(id, path string) {
+		node, ok := tree.Nodes[id]
+		if !ok {
+			return
+		}
+		if _, ok := paths[id]; ok {
+			return
+		}
+		paths[id] = path
+		order = append(order, id)
+		for i, c := range children(node) {
+			if _, ok := parents[c]; ok {
+				continue
+			}
+			parents[c] = id
+			title := tree.Nodes[c].Title
+			walk(c, fmt.Sprintf("%s > [%d] %s", path, i, title))
+		}
+	}
+	if root, ok := tree.Nodes[tree.Root]; ok {
+		walk(tree.Root, root.Title)
+	}
+	return order
+}
+
+func pathOr(paths map[string]string, id string, tree *config.BTTreeCfg) string {
+	if p, ok := paths[id]; ok {
+		return p
+	}
+	return "(detached) " + tree.Nodes[id].Title
+}
+
+func children(node config.BTNodeCfg) []string {
+	if node.Child != "" {
+		return []string{node.Child}
+	}
+	return node.Children
+}
+
+func nodeType(node config.BTNodeCfg) string {
+	if node.Category == "" {
+		return node.Name
+	}
+	return node.Name + "(" + node.Category + ")"
+}
@@ -0,0 +1,131 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/diff"
+)
+
+func parseTree(t *testing.T, src string) *config.BTTreeCfg {
+	t.Helper()
+	tree, err := config.ParseTreeText(src)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, src)
+	}
+	return tree
+}
+
+func changeStrings(changes []diff.Change) []string {
+	var result []string
+	for _, c := range changes {
+		result = append(result, c.String())
+	}
+	return result
+}
+
+func sameChanges(t *testing.T, changes []diff.Change, want []string) {
+	t.Helper()
+	if got := changeStrings(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+const patrol = `tree "t"
+  Sequence @id=root
+    Log @id=a info=x
+    Wait @id=b milliseconds=1
+    Inverter @id=c
+      Failer @id=d
+`
+
+func TestDiffSameTree(t *testing.T) {
+	sameChanges(t, diff.DiffTrees(parseTree(t, patrol), parseTree(t, patrol)), nil)
+}
+
+func TestDiffNodes(t *testing.T) {
+	b := `tree "t"
+  Sequence @id=root
+    Wait @id=b milliseconds=2
+    Log "log" @id=a info=x
+    Inverter @id=c
+      Succeeder @id=d
+    Failer @id=e
+`
+	changes := diff.DiffTrees(parseTree(t, patrol), parseTree(t, b))
+	sameChanges(t, changes, []string{
+		`[t] children reordered Sequence: [log, Wait, Inverter] -> [Wait, log, Inverter]`,
+		`[t] property changed Sequence > [0] Wait: milliseconds 1 -> 2`,
+		`[t] title changed Sequence > [1] log: "Log" -> "log"`,
+		`[t] type changed Sequence > [2] Inverter > [0] Succeeder: "Failer(action)" -> "Succeeder(action)"`,
+		`[t] title changed Sequence > [2] Inverter > [0] Succeeder: "Failer" -> "Succeeder"`,
+		`[t] added Sequence > [3] Failer`,
+	})
+	if c := changes[1]; c.Kind != diff.PropertyChanged || c.NodeID != "b" || c.Property != "milliseconds" || c.Old != 1.0 || c.New != 2.0 {
+		t.Errorf("property change = %+v", c)
+	}
+}
+
+func TestDiffMovedAndRemoved(t *testing.T) {
+	b := `tree "t"
+  Sequence @id=root
+    Inverter @id=c
+      Wait @id=b milliseconds=1
+`
+	sameChanges(t, diff.DiffTrees(parseTree(t, patrol), parseTree(t, b)), []string{
+		`[t] moved Sequence > [0] Inverter > [0] Wait: from "Sequence > [1] Wait"`,
+		`[t] removed Sequence > [0] Log`,
+		`[t] removed Sequence > [2] Inverter > [0] Failer`,
+	})
+}
+
+func TestDiffRegeneratedIDs(t *testing.T) {
+	//nodes without matching IDs are matched by their position under matched parents
+	a := "tree \"t\"\n  Sequence\n    Log info=x\n    Inverter\n      Wait\n"
+	b := "tree \"t\"\n  Sequence @id=x1\n    Log @id=x2 info=y\n    Inverter @id=x3\n    Wait @id=x4\n"
+	sameChanges(t, diff.DiffTrees(parseTree(t, a), parseTree(t, b)), []string{
+		`[t] property changed Sequence > [0] Log: info "x" -> "y"`,
+		`[t] added Sequence > [2] Wait`,
+		`[t] removed Sequence > [1] Inverter > [0] Wait`,
+	})
+}
+
+func TestDiffTreeAttributes(t *testing.T) {
+	a := "tree \"t\" @id=t speed=1\n  Log\n"
+	b := "tree \"patrol\" @id=t @desc=\"walk around\" speed=2 range=3\n  Log\n"
+	sameChanges(t, diff.DiffTrees(parseTree(t, a), parseTree(t, b)), []string{
+		`[patrol] tree changed: title "t" -> "patrol"`,
+		`[patrol] tree changed: description "" -> "walk around"`,
+		`[patrol] tree changed: range (none) -> 3`,
+		`[patrol] tree changed: speed 1 -> 2`,
+	})
+}
+
+func TestDiffDetachedNodes(t *testing.T) {
+	a := "tree \"t\"\n  Sequence @id=root\n  detached Log @id=a\n  detached Wait @id=b\n"
+	b := "tree \"t\"\n  Sequence @id=root\n    Log @id=a\n"
+	sameChanges(t, diff.DiffTrees(parseTree(t, a), parseTree(t, b)), []string{
+		`[t] moved Sequence > [0] Log: from "(detached) Log"`,
+		`[t] removed (detached) Wait`,
+	})
+}
+
+func TestDiffProjects(t *testing.T) {
+	a, err := config.ParseTreesText("tree \"a\" @id=1\n  Log\ntree \"b\" @id=2\n  Log\ntree \"c\" @id=3\n  Log\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//a keeps its ID, b is matched by title after the editor changed its ID
+	b, err := config.ParseTreesText("tree \"b\" @id=20\n  Wait\ntree \"a\" @id=1\n  Log\ntree \"d\" @id=4\n  Log\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := diff.DiffProjects(&config.BTProjectCfg{Trees: a}, &config.BTProjectCfg{Trees: b})
+	sameChanges(t, changes, []string{
+		`[b] type changed Wait: "Log(action)" -> "Wait(action)"`,
+		`[b] title changed Wait: "Log" -> "Wait"`,
+		`[d] tree added`,
+		`[c] tree removed`,
+	})
+}
@@ -20,22 +20,19 @@ func main() {
 
 	//自定义节点注册
 	maps := core.NewRegisterStructMaps()
-	maps.Register("Log", new(share.LogTest))
+	maps.Register("Log", func() core.IBaseNode { return new(share.LogTest) })
 
-	var firstTree *core.BehaviorTree
 	//载入
-	for _, v := range projectConfig.Trees {
-		tree := loader.CreateBevTreeFromConfig(&v, maps)
+	project := loader.CreateProjectFromConfig(projectConfig, maps)
+	for _, tree := range project.GetTrees() {
 		tree.Print()
-		if firstTree == nil {
-			firstTree = tree
-		}
 	}
+	firstTree := project.SelectedTree()
 
 	//输入板
 	board := core.NewBlackboard()
 	//循环每一帧
 	for i := 0; i < 5; i++ {
-		firstTree.Tick(i, board)
+		firstTree.Tick(core.NewTick(), board)
 	}
 }
@@ -19,7 +19,7 @@ func main() {
 	}
 	//自定义节点注册
 	maps := core.NewRegisterStructMaps()
-	maps.Register("Log", new(share.LogTest))
+	maps.Register("Log", func() core.IBaseNode { return new(share.LogTest) })
 
 	//载入
 	tree := loader.CreateBevTreeFromConfig(treeConfig, maps)
@@ -29,6 +29,6 @@ func main() {
 	board := core.NewBlackboard()
 	//循环每一帧
 	for i := 0; i < 5; i++ {
-		tree.Tick(i, board)
+		tree.Tick(core.NewTick(), board)
 	}
 }
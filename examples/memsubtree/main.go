@@ -9,29 +9,17 @@ import (
 	"github.com/magicsea/behavior3go/core"
 	"github.com/magicsea/behavior3go/examples/share"
 	"github.com/magicsea/behavior3go/loader"
-	"sync"
+	"os"
 	"time"
 )
 
-//所有的树管理
-var mapTreesByID = sync.Map{}
 var maps = core.NewRegisterStructMaps()
 
 func init() {
 	//自定义节点注册
-	maps.Register("Log", new(share.LogTest))
-	maps.Register("SetValue", new(share.SetValue))
-	maps.Register("IsValue", new(share.IsValue))
-
-	//获取子树的方法
-	core.SetSubTreeLoadFunc(func(id string) *core.BehaviorTree {
-		//println("==>load subtree:",id)
-		t, ok := mapTreesByID.Load(id)
-		if ok {
-			return t.(*core.BehaviorTree)
-		}
-		return nil
-	})
+	maps.Register("Log", func() core.IBaseNode { return new(share.LogTest) })
+	maps.Register("SetValue", func() core.IBaseNode { return new(share.SetValue) })
+	maps.Register("IsValue", func() core.IBaseNode { return new(share.IsValue) })
 }
 
 func main() {
@@ -41,24 +29,22 @@ func main() {
 		return
 	}
 
-	var firstTree *core.BehaviorTree
-	//载入
-	for _, v := range projectConfig.Data.Trees {
-		tree := loader.CreateBevTreeFromConfig(&v, maps)
+	//载入,子树通过工程查找
+	project := loader.CreateProjectFromRawConfig(projectConfig, maps)
+	for _, tree := range project.GetTrees() {
 		tree.Print()
-		//保存到树管理
-		println("==>store subtree:", v.ID)
-		mapTreesByID.Store(v.ID, tree)
-		if firstTree == nil {
-			firstTree = tree
-		}
 	}
+	firstTree := project.GetTrees()[0]
 
+	//记录每个节点上次的状态,打印时显示
+	firstTree.SetTrackStatus(true)
 	//输入板
 	board := core.NewBlackboard()
 	//循环每一帧
 	for i := 0; i < 100; i++ {
-		firstTree.Tick(i, board)
+		firstTree.Tick(core.NewTick(), board)
 		time.Sleep(time.Millisecond * 100)
 	}
+	//打印每个节点的运行状态
+	firstTree.Fprint(os.Stdout, board)
 }
@@ -3,22 +3,16 @@ package share
 import (
 	"fmt"
 	b3 "github.com/magicsea/behavior3go"
-	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
 )
 
 //自定义action节点
 type LogTest struct {
 	core.Action
-	info string
-}
-
-func (this *LogTest) Initialize(setting *config.BTNodeCfg) {
-	this.Action.Initialize(setting)
-	this.info = setting.GetPropertyAsString("info")
+	Info string `b3:"info,required"`
 }
 
 func (this *LogTest) OnTick(tick core.Ticker) b3.Status {
-	fmt.Println("logtest:", tick.GetLastSubTree(), this.info)
+	fmt.Println("logtest:", tick.GetLastSubTree(), this.Info)
 	return b3.SUCCESS
 }
@@ -9,24 +9,8 @@ import (
 	"github.com/magicsea/behavior3go/core"
 	"github.com/magicsea/behavior3go/examples/share"
 	"github.com/magicsea/behavior3go/loader"
-	"sync"
 )
 
-//所有的树管理
-var mapTreesByID = sync.Map{}
-
-func init() {
-	//获取子树的方法
-	core.SetSubTreeLoadFunc(func(id string) *core.BehaviorTree {
-		fmt.Println("==>load subtree:", id)
-		t, ok := mapTreesByID.Load(id)
-		if ok {
-			return t.(*core.BehaviorTree)
-		}
-		return nil
-	})
-}
-
 func main() {
 	projectConfig, ok := config.LoadRawProjectCfg("example.b3")
 	if !ok {
@@ -36,25 +20,19 @@ func main() {
 
 	//自定义节点注册
 	maps := core.NewRegisterStructMaps()
-	maps.Register("Log", new(share.LogTest))
+	maps.Register("Log", func() core.IBaseNode { return new(share.LogTest) })
 
-	var firstTree *core.BehaviorTree
-	//载入
-	for _, v := range projectConfig.Data.Trees {
-		tree := loader.CreateBevTreeFromConfig(&v, maps)
+	//载入,子树通过工程查找
+	project := loader.CreateProjectFromRawConfig(projectConfig, maps)
+	for _, tree := range project.GetTrees() {
 		tree.Print()
-		//保存到树管理
-		println("==>store subtree:", v.ID)
-		mapTreesByID.Store(v.ID, tree)
-		if firstTree == nil {
-			firstTree = tree
-		}
 	}
+	firstTree := project.SelectedTree()
 
 	//输入板
 	board := core.NewBlackboard()
 	//循环每一帧
 	for i := 0; i < 5; i++ {
-		firstTree.Tick(i, board)
+		firstTree.Tick(core.NewTick(), board)
 	}
 }
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+//Graphviz的节点形状
+var dotShapes = map[string]string{
+	b3.COMPOSITE:    `shape=box, style="rounded,filled", fillcolor="#d6e4f0"`,
+	b3.DECORATOR:    `shape=hexagon, style=filled, fillcolor="#f0e6d6"`,
+	b3.ACTION:       `shape=box, style=filled, fillcolor="#e2f0d6"`,
+	b3.CONDITION:    `shape=ellipse, style=filled, fillcolor="#f0d6d6"`,
+	subtreeCategory: `shape=component, style=filled, fillcolor="#e6e6e6"`,
+}
+
+//把树配置渲染为DOT
+func DOT(tree *config.BTTreeCfg, opts *Options) string {
+	return renderDOT(fromConfig(tree, opts, make(map[*config.BTTreeCfg]bool)), opts)
+}
+
+//把加载好的树渲染为DOT,子树通过所属的工程展开
+func TreeDOT(tree *core.BehaviorTree, opts *Options) string {
+	return renderDOT(fromBehaviorTree(tree, opts, make(map[*core.BehaviorTree]bool)), opts)
+}
+
+func renderDOT(g *graphTree, opts *Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.title))
+	fmt.Fprintf(&b, "  rankdir=%s;\n  ordering=out;\n", opts.direction())
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=11];\n")
+	num := &numbering{ids: make(map[*graphNode]string)}
+	clusters := 0
+	var write func(n *graphNode, indent string)
+	write = func(n *graphNode, indent string) {
+		id := num.id(n)
+		fmt.Fprintf(&b, "%s%s [label=%s, %s];\n", indent, id, dotQuote(n.label), dotShape(n.category))
+		if n.subtree != nil && n.subtree.root != nil {
+			fmt.Fprintf(&b, "%ssubgraph cluster_%d {\n", indent, clusters)
+			clusters++
+			fmt.Fprintf(&b, "%s  label=%s;\n%s  style=dashed;\n", indent, dotQuote(n.subtree.title), indent)
+			write(n.subtree.root, indent+"  ")
+			fmt.Fprintf(&b, "%s}\n", indent)
+			fmt.Fprintf(&b, "%s%s -> %s [style=dashed];\n", indent, id, num.id(n.subtree.root))
+		}
+		for _, c := range n.children {
+			write(c, indent)
+			fmt.Fprintf(&b, "%s%s -> %s;\n", indent, id, num.id(c))
+		}
+	}
+	if g.root != nil {
+		write(g.root, "  ")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotShape(category string) string {
+	if shape, ok := dotShapes[category]; ok {
+		return shape
+	}
+	return "shape=box"
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
@@ -0,0 +1,54 @@
+package graph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/magicsea/behavior3go/graph"
+)
+
+func TestDOT(t *testing.T) {
+	project := graphTrees(t)
+	got := graph.DOT(&project.Trees[0], &graph.Options{InlineSubtrees: true, Resolve: project.FindTree})
+	want := `digraph "main" {
+  rankdir=TB;
+  ordering=out;
+  node [fontname="Helvetica", fontsize=11];
+  n0 [label="Priority", shape=box, style="rounded,filled", fillcolor="#d6e4f0"];
+  n1 [label="Inverter", shape=hexagon, style=filled, fillcolor="#f0e6d6"];
+  n2 [label="Is ready set?", shape=ellipse, style=filled, fillcolor="#f0d6d6"];
+  n1 -> n2;
+  n0 -> n1;
+  n3 [label="Wait 500ms", shape=box, style=filled, fillcolor="#e2f0d6"];
+  n0 -> n3;
+  n4 [label="child", shape=component, style=filled, fillcolor="#e6e6e6"];
+  subgraph cluster_0 {
+    label="child";
+    style=dashed;
+    n5 [label="Sequence", shape=box, style="rounded,filled", fillcolor="#d6e4f0"];
+    n6 [label="Log\ninfo: done", shape=box, style=filled, fillcolor="#e2f0d6"];
+    n5 -> n6;
+  }
+  n4 -> n5 [style=dashed];
+  n0 -> n4;
+}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDOTWithoutSubtrees(t *testing.T) {
+	project := graphTrees(t)
+	got := graph.DOT(&project.Trees[0], &graph.Options{Resolve: project.FindTree, MaxProperties: -1, Direction: "LR"})
+	if strings.Contains(got, "cluster") || strings.Contains(got, "Sequence") {
+		t.Errorf("subtree expanded:\n%s", got)
+	}
+	if !strings.Contains(got, "rankdir=LR;") {
+		t.Errorf("direction not set:\n%s", got)
+	}
+	//属性不显示,标题里的<key>仍然替换
+	if !strings.Contains(got, `[label="Wait 500ms", shape=box`) {
+		t.Errorf("label:\n%s", got)
+	}
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+//Mermaid的节点形状,左右括号
+var mermaidShapes = map[string][2]string{
+	b3.COMPOSITE:    {"(", ")"},
+	b3.DECORATOR:    {"{{", "}}"},
+	b3.ACTION:       {"[", "]"},
+	b3.CONDITION:    {"([", "])"},
+	subtreeCategory: {"[[", "]]"},
+}
+
+//把树配置渲染为Mermaid流程图
+func Mermaid(tree *config.BTTreeCfg, opts *Options) string {
+	return renderMermaid(fromConfig(tree, opts, make(map[*config.BTTreeCfg]bool)), opts)
+}
+
+//把加载好的树渲染为Mermaid流程图,子树通过所属的工程展开
+func TreeMermaid(tree *core.BehaviorTree, opts *Options) string {
+	return renderMermaid(fromBehaviorTree(tree, opts, make(map[*core.BehaviorTree]bool)), opts)
+}
+
+func renderMermaid(g *graphTree, opts *Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "flowchart %s\n", opts.direction())
+	num := &numbering{ids: make(map[*graphNode]string)}
+	clusters := 0
+	var write func(n *graphNode, indent string)
+	write = func(n *graphNode, indent string) {
+		id := num.id(n)
+		shape, ok := mermaidShapes[n.category]
+		if !ok {
+			shape = mermaidShapes[b3.ACTION]
+		}
+		fmt.Fprintf(&b, "%s%s%s%s%s\n", indent, id, shape[0], mermaidQuote(n.label), shape[1])
+		if n.subtree != nil && n.subtree.root != nil {
+			fmt.Fprintf(&b, "%ssubgraph s%d [%s]\n", indent, clusters, mermaidQuote(n.subtree.title))
+			clusters++
+			write(n.subtree.root, indent+"  ")
+			fmt.Fprintf(&b, "%send\n", indent)
+			fmt.Fprintf(&b, "%s%s -.-> %s\n", indent, id, num.id(n.subtree.root))
+		}
+		for _, c := range n.children {
+			write(c, indent)
+			fmt.Fprintf(&b, "%s%s --> %s\n", indent, id, num.id(c))
+		}
+	}
+	if g.root != nil {
+		write(g.root, "  ")
+	}
+	return b.String()
+}
+
+func mermaidQuote(s string) string {
+	s = strings.Replace(s, "&", "#amp;", -1)
+	s = strings.Replace(s, `"`, "#quot;", -1)
+	s = strings.Replace(s, "<", "#lt;", -1)
+	s = strings.Replace(s, ">", "#gt;", -1)
+	s = strings.Replace(s, "\n", "<br/>", -1)
+	return `"` + s + `"`
+}
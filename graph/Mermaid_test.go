@@ -0,0 +1,49 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/graph"
+)
+
+func TestMermaid(t *testing.T) {
+	project := graphTrees(t)
+	got := graph.Mermaid(&project.Trees[0], &graph.Options{InlineSubtrees: true, Resolve: project.FindTree})
+	want := `flowchart TB
+  n0("Priority")
+  n1{{"Inverter"}}
+  n2(["Is ready set?"])
+  n1 --> n2
+  n0 --> n1
+  n3["Wait 500ms"]
+  n0 --> n3
+  n4[["child"]]
+  subgraph s0 ["child"]
+    n5("Sequence")
+    n6["Log<br/>info: done"]
+    n5 --> n6
+  end
+  n4 -.-> n5
+  n0 --> n4
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMermaidQuotesLabels(t *testing.T) {
+	//没有对应属性的<key>保持原样并转义
+	tree, err := config.ParseTreeText(`tree "t"
+  Log "Say \"hi\" to <name> & leave" info=hi
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `flowchart TB
+  n0["Say #quot;hi#quot; to #lt;name#gt; #amp; leave<br/>info: hi"]
+`
+	if got := graph.Mermaid(tree, nil); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+/**
+ * Renders trees as Graphviz DOT or Mermaid flowcharts, for design documents
+ * and review comments:
+ *
+ *     fmt.Println(graph.Mermaid(treeCfg, &graph.Options{
+ *       InlineSubtrees: true,
+ *       Resolve:        projectCfg.FindTree,
+ *     }))
+ *
+ * Nodes are shaped by category and labeled with the title and the
+ * properties. A `<key>` placeholder in the title is replaced by the
+ * property value, as the editor does.
+ *
+ * @module graph
+**/
+
+//节点分类,和b3的分类一致,子树为tree
+const subtreeCategory = "tree"
+
+type Options struct {
+	//子树展开为子图
+	InlineSubtrees bool
+	//按名字或标题查找子树,只用于config.BTTreeCfg
+	Resolve func(name string) *config.BTTreeCfg
+	//标签里的属性个数上限,0显示全部,小于0不显示
+	MaxProperties int
+	//布局方向,TB(默认)或LR
+	Direction string
+}
+
+type graphNode struct {
+	category string
+	label    string
+	children []*graphNode
+	//展开的子树
+	subtree *graphTree
+}
+
+type graphTree struct {
+	title string
+	root  *graphNode
+}
+
+func (o *Options) direction() string {
+	if o == nil || o.Direction == "" {
+		return "TB"
+	}
+	return o.Direction
+}
+
+func (o *Options) maxProperties() int {
+	if o == nil {
+		return 0
+	}
+	return o.MaxProperties
+}
+
+func (o *Options) inline() bool {
+	return o != nil && o.InlineSubtrees
+}
+
+//从配置生成图,visiting用来避免子树递归展开
+func fromConfig(tree *config.BTTreeCfg, opts *Options, visiting map[*config.BTTreeCfg]bool) *graphTree {
+	visiting[tree] = true
+	defer delete(visiting, tree)
+	g := &graphTree{title: tree.Title}
+	done := make(map[string]bool)
+	var build func(id string) *graphNode
+	build = func(id string) *graphNode {
+		cfg, ok := tree.Nodes[id]
+		if !ok || done[id] {
+			return nil
+		}
+		done[id] = true
+		n := &graphNode{category: configCategory(&cfg)}
+		n.label = nodeLabel(cfg.Name, cfg.Title, cfg.Properties, opts.maxProperties())
+		if n.category == subtreeCategory && opts.inline() && opts.Resolve != nil {
+			sub := opts.Resolve(cfg.Name)
+			if sub == nil {
+				sub = opts.Resolve(cfg.Title)
+			}
+			if sub != nil && !visiting[sub] {
+				n.subtree = fromConfig(sub, opts, visiting)
+			}
+		}
+		var children []string
+		if cfg.Child != "" {
+			children = append(children, cfg.Child)
+		}
+		for _, c := range append(children, cfg.Children...) {
+			if child := build(c); child != nil {
+				n.children = append(n.children, child)
+			}
+		}
+		return n
+	}
+	g.root = build(tree.Root)
+	return g
+}
+
+func configCategory(cfg *config.BTNodeCfg) string {
+	switch {
+	case cfg.Category != "":
+		return cfg.Category
+	case cfg.Child != "":
+		return b3.DECORATOR
+	case len(cfg.Children) > 0:
+		return b3.COMPOSITE
+	}
+	return b3.ACTION
+}
+
+//从加载好的树生成图
+func fromBehaviorTree(tree *core.BehaviorTree, opts *Options, visiting map[*core.BehaviorTree]bool) *graphTree {
+	visiting[tree] = true
+	defer delete(visiting, tree)
+	g := &graphTree{title: tree.GetTitile()}
+	var build func(node core.IBaseNode) *graphNode
+	build = func(node core.IBaseNode) *graphNode {
+		var props map[string]interface{}
+		if p, ok := node.(interface {
+			GetProperties() map[string]interface{}
+		}); ok {
+			props = p.GetProperties()
+		}
+		n := &graphNode{category: node.GetCategory()}
+		n.label = nodeLabel(node.GetName(), node.GetTitle(), props, opts.maxProperties())
+		if st, ok := node.(*core.SubTree); ok {
+			n.category = subtreeCategory
+			if opts.inline() {
+				if sub := st.GetSubTree(); sub != nil && !visiting[sub] {
+					n.subtree = fromBehaviorTree(sub, opts, visiting)
+				}
+			}
+			return n
+		}
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			comp := node.(core.IComposite)
+			for i := 0; i < comp.GetChildCount(); i++ {
+				n.children = append(n.children, build(comp.GetChild(i)))
+			}
+		case b3.DECORATOR:
+			if child := node.(core.IDecorator).GetChild(); child != nil {
+				n.children = append(n.children, build(child))
+			}
+		}
+		return n
+	}
+	if tree.GetRoot() != nil {
+		g.root = build(tree.GetRoot())
+	}
+	return g
+}
+
+//标题和属性组成的标签,标题里的<key>替换为属性值
+func nodeLabel(name, title string, props map[string]interface{}, max int) string {
+	if title == "" {
+		title = name
+	}
+	title, rest := core.ExpandTitle(title, props, formatProperty)
+	lines := []string{title}
+	for _, k := range rest {
+		if max < 0 || max > 0 && len(lines) > max {
+			break
+		}
+		lines = append(lines, k+": "+formatProperty(props[k]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatProperty(v interface{}) string {
+	var s string
+	switch tv := v.(type) {
+	case string:
+		s = tv
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(tv)
+		s = string(data)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if r := []rune(s); len(r) > 32 {
+		s = string(r[:31]) + "…"
+	}
+	return s
+}
+
+//按深度优先的顺序给节点编号
+type numbering struct {
+	ids  map[*graphNode]string
+	next int
+}
+
+func (n *numbering) id(node *graphNode) string {
+	if id, ok := n.ids[node]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", n.next)
+	n.next++
+	n.ids[node] = id
+	return id
+}
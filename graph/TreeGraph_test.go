@@ -0,0 +1,29 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/magicsea/behavior3go/config"
+)
+
+//主树通过子树节点引用child,每种分类都有节点
+const graphProject = `tree "main" @id=main
+  Priority
+    Inverter
+      Check "Is <key> set?" @category=condition key=ready
+    Wait "Wait <milliseconds>ms" milliseconds=500
+    subtree child "child"
+
+tree "child" @id=child
+  Sequence
+    Log info=done
+`
+
+func graphTrees(t *testing.T) *config.BTProjectCfg {
+	t.Helper()
+	trees, err := config.ParseTreesText(graphProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.BTProjectCfg{Trees: trees}
+}
@@ -0,0 +1,227 @@
+package loader
+
+import (
+	"fmt"
+	"sort"
+
+	b3 "github.com/magicsea/behavior3go"
+	"github.com/magicsea/behavior3go/composites"
+	"github.com/magicsea/behavior3go/config"
+	"github.com/magicsea/behavior3go/core"
+)
+
+// Validate a tree before loading it. Unlike BehaviorTree.Load, which panics
+// on the first problem, all problems are returned. resolve reports whether a
+// subtree name exists, nil skips the subtree check.
+func ValidateTree(tree *config.BTTreeCfg, extMap *core.RegisterStructMaps, resolve func(name string) bool) []error {
+	var errs []error
+	fail := func(node *config.BTNodeCfg, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		if node != nil {
+			msg = fmt.Sprintf("node %s(%s): %s", node.Name, node.Id, msg)
+		}
+		errs = append(errs, fmt.Errorf("tree %s: %s", tree.Title, msg))
+	}
+
+	if _, ok := tree.Nodes[tree.Root]; !ok {
+		fail(nil, "root node %q not found", tree.Root)
+	}
+	baseMap := createBaseFactoryMaps()
+	parents := make(map[string]string)
+	nodes := make(map[string]core.IBaseNode)
+	for _, id := range sortedNodeIDs(tree) {
+		nodeCfg := tree.Nodes[id]
+		var children []string
+		if nodeCfg.Child != "" {
+			children = append(children, nodeCfg.Child)
+		}
+		children = append(children, nodeCfg.Children...)
+		for _, c := range children {
+			if _, ok := tree.Nodes[c]; !ok {
+				fail(&nodeCfg, "child %q not found", c)
+			} else if p, ok := parents[c]; ok && p != id {
+				fail(&nodeCfg, "child %q is also a child of %q", c, p)
+			} else {
+				parents[c] = id
+			}
+		}
+
+		if nodeCfg.Category == "tree" {
+			if resolve != nil && !resolve(nodeCfg.Name) && !resolve(nodeCfg.Title) {
+				fail(&nodeCfg, "subtree not found")
+			}
+			continue
+		}
+		var creator core.NodeCreator
+		if extMap != nil && extMap.CheckNode(nodeCfg.Name) {
+			creator = extMap.GetNode(nodeCfg.Name)
+		} else if c, ok := baseMap[nodeCfg.Name]; ok {
+			creator = c
+		}
+		if creator == nil {
+			fail(&nodeCfg, "unknown node name")
+			continue
+		}
+		node := creator()
+		node.Ctor()
+		if err := core.BindProperties(node, &nodeCfg); err != nil {
+			fail(&nodeCfg, "%v", err)
+		}
+		nodes[id] = node
+		if mode, ok := nodeCfg.Properties["abort"]; ok {
+			s, _ := mode.(string)
+			if abort, err := composites.ParseAbortMode(s); err != nil {
+				fail(&nodeCfg, "%v", err)
+			} else if abort != composites.AbortNone {
+				if c := node.GetCategory(); c != b3.CONDITION && c != b3.DECORATOR {
+					fail(&nodeCfg, "abort is only supported on conditions and decorators")
+				} else if keys, err := composites.ObservedKeys(node, nodeCfg.Properties); err != nil {
+					fail(&nodeCfg, "%v", err)
+				} else if len(keys) == 0 {
+					fail(&nodeCfg, "abort without observed blackboard keys")
+				}
+			}
+		}
+		switch node.GetCategory() {
+		case b3.COMPOSITE:
+			if nodeCfg.Child != "" {
+				fail(&nodeCfg, "composite uses children, child is ignored")
+			}
+		case b3.DECORATOR:
+			if len(nodeCfg.Children) > 0 {
+				fail(&nodeCfg, "decorator uses child, children are ignored")
+			}
+		default:
+			if len(children) > 0 {
+				fail(&nodeCfg, "%s cannot have children", node.GetCategory())
+			}
+		}
+	}
+	if _, ok := parents[tree.Root]; ok {
+		fail(nil, "root node %q has a parent", tree.Root)
+	}
+	// a cooperative Parallel never cancels its blocking descendants
+	for _, id := range sortedNodeIDs(tree) {
+		if p, ok := nodes[id].(*composites.Parallel); ok && p.Cooperative {
+			if b := blockingDescendant(tree, id, nodes); b != "" {
+				nodeCfg, blocking := tree.Nodes[id], tree.Nodes[b]
+				fail(&nodeCfg, "cooperative Parallel cannot cancel the blocking %s(%s)", blocking.Name, b)
+			}
+		}
+	}
+	return errs
+}
+
+//在一次tick里等待运行的子节点结束的节点
+func blocksTick(node core.IBaseNode) bool {
+	switch n := node.(type) {
+	case *composites.Sequence, *composites.Subscription:
+		return true
+	case *composites.MemSequence:
+		return !n.NonBlocking
+	case *composites.MemPriority:
+		return !n.NonBlocking
+	}
+	return false
+}
+
+//第一个阻塞的子孙节点,Parallel和Race用自己的cancelCtx取消下面的节点
+func blockingDescendant(tree *config.BTTreeCfg, id string, nodes map[string]core.IBaseNode) string {
+	visited := map[string]bool{id: true}
+	var walk func(id string) string
+	walk = func(id string) string {
+		nodeCfg := tree.Nodes[id]
+		children := append([]string{}, nodeCfg.Children...)
+		if nodeCfg.Child != "" {
+			children = append(children, nodeCfg.Child)
+		}
+		for _, c := range children {
+			if visited[c] {
+				continue
+			}
+			visited[c] = true
+			switch nodes[c].(type) {
+			case *composites.Parallel, *composites.Race:
+				//单线程的Parallel单独检查
+				continue
+			}
+			if blocksTick(nodes[c]) {
+				return c
+			}
+			if b := walk(c); b != "" {
+				return b
+			}
+		}
+		return ""
+	}
+	return walk(id)
+}
+
+// Validate all the trees of a project and the subtree references between them
+func ValidateProject(project *config.BTProjectCfg, extMap *core.RegisterStructMaps) []error {
+	byKey := make(map[string]*config.BTTreeCfg)
+	for i := range project.Trees {
+		byKey[project.Trees[i].Title] = &project.Trees[i]
+	}
+	for i := range project.Trees {
+		byKey[project.Trees[i].ID] = &project.Trees[i]
+	}
+	resolve := func(name string) bool {
+		_, ok := byKey[name]
+		return ok
+	}
+
+	var errs []error
+	if project.Select != "" && !resolve(project.Select) {
+		errs = append(errs, fmt.Errorf("selected tree %q not found", project.Select))
+	}
+	for i := range project.Trees {
+		errs = append(errs, ValidateTree(&project.Trees[i], extMap, resolve)...)
+	}
+
+	// a subtree must not include itself
+	state := make(map[*config.BTTreeCfg]int)
+	var visit func(tree *config.BTTreeCfg, path string) error
+	visit = func(tree *config.BTTreeCfg, path string) error {
+		switch state[tree] {
+		case 1:
+			return fmt.Errorf("subtree cycle: %s", path)
+		case 2:
+			return nil
+		}
+		state[tree] = 1
+		for _, id := range sortedNodeIDs(tree) {
+			nodeCfg := tree.Nodes[id]
+			if nodeCfg.Category != "tree" {
+				continue
+			}
+			sub, ok := byKey[nodeCfg.Name]
+			if !ok {
+				sub, ok = byKey[nodeCfg.Title]
+			}
+			if ok {
+				if err := visit(sub, path+" > "+sub.Title); err != nil {
+					return err
+				}
+			}
+		}
+		state[tree] = 2
+		return nil
+	}
+	for i := range project.Trees {
+		if err := visit(&project.Trees[i], project.Trees[i].Title); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+	return errs
+}
+
+func sortedNodeIDs(tree *config.BTTreeCfg) []string {
+	ids := make([]string, 0, len(tree.Nodes))
+	for id := range tree.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -1,8 +1,10 @@
 package loader
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"github.com/magicsea/behavior3go/actions"
+	"github.com/magicsea/behavior3go/bundle"
 	"github.com/magicsea/behavior3go/composites"
 	"github.com/magicsea/behavior3go/config"
 	"github.com/magicsea/behavior3go/core"
@@ -44,6 +46,39 @@ func createBaseFactoryMaps() map[string]core.NodeCreator {
 	result["Parallel"] = func() core.IBaseNode {
 		return &composites.Parallel{}
 	}
+	result["RandomSequence"] = func() core.IBaseNode {
+		return &composites.RandomSequence{}
+	}
+	result["RandomSelector"] = func() core.IBaseNode {
+		return &composites.RandomSelector{}
+	}
+	result["WeightedRandomSelector"] = func() core.IBaseNode {
+		return &composites.WeightedRandomSelector{}
+	}
+	result["UtilitySelector"] = func() core.IBaseNode {
+		return &composites.UtilitySelector{}
+	}
+	result["ReactiveSequence"] = func() core.IBaseNode {
+		return &composites.ReactiveSequence{}
+	}
+	result["ReactiveFallback"] = func() core.IBaseNode {
+		return &composites.ReactiveFallback{}
+	}
+	result["Switch"] = func() core.IBaseNode {
+		return &composites.Switch{}
+	}
+	result["IfThenElse"] = func() core.IBaseNode {
+		return &composites.IfThenElse{}
+	}
+	result["WhileDoElse"] = func() core.IBaseNode {
+		return &composites.WhileDoElse{}
+	}
+	result["ConfigurableSequence"] = func() core.IBaseNode {
+		return &composites.ConfigurableSequence{}
+	}
+	result["Race"] = func() core.IBaseNode {
+		return &composites.Race{}
+	}
 	result["Inverter"] = func() core.IBaseNode {
 		return &decorators.Inverter{}
 	}
@@ -72,6 +107,28 @@ func CreateBevTreeFromConfig(config *config.BTTreeCfg, extMap *core.RegisterStru
 	return tree
 }
 
+func CreateProjectFromConfig(config *config.BTProjectCfg, extMap *core.RegisterStructMaps) *core.Project {
+	baseMaps := createBaseFactoryMaps()
+	project := core.NewProject()
+	project.Load(config, baseMaps, extMap)
+	return project
+}
+
+func CreateProjectFromConfigWithOptions(config *config.BTProjectCfg, extMap *core.RegisterStructMaps, opts core.LoadOptions) *core.Project {
+	baseMaps := createBaseFactoryMaps()
+	project := core.NewProject()
+	project.LoadWithOptions(config, baseMaps, extMap, opts)
+	return project
+}
+
+func CreateProjectFromRawConfig(config *config.RawProjectCfg, extMap *core.RegisterStructMaps) *core.Project {
+	data := config.Data
+	if data.ID == "" {
+		data.ID = config.Name
+	}
+	return CreateProjectFromConfig(&data, extMap)
+}
+
 // Check Tree Nodes
 func CheckTreeComplete(trees []config.BTTreeCfg, extMap *core.RegisterStructMaps) error {
 	baseMap := createBaseFactoryMaps()
@@ -92,3 +149,66 @@ func CheckTreeComplete(trees []config.BTTreeCfg, extMap *core.RegisterStructMaps
 	}
 	return nil
 }
+
+// Check Tree Node Properties against the b3 tags of the nodes
+func CheckTreeProperties(trees []config.BTTreeCfg, extMap *core.RegisterStructMaps) error {
+	baseMap := createBaseFactoryMaps()
+	for _, tree := range trees {
+		for _, nodeCfg := range tree.Nodes {
+			var creator core.NodeCreator
+			if extMap != nil && extMap.CheckNode(nodeCfg.Name) {
+				creator = extMap.GetNode(nodeCfg.Name)
+			} else if c, ok := baseMap[nodeCfg.Name]; ok {
+				creator = c
+			}
+			if creator == nil {
+				continue
+			}
+			if err := core.BindProperties(creator(), &nodeCfg); err != nil {
+				return fmt.Errorf("tree %s node %s(%s): %v", tree.Title, nodeCfg.Name, nodeCfg.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Export the registered nodes as editor custom nodes
+func ExportCustomNodes(extMap *core.RegisterStructMaps) ([]config.BTCustomNodeCfg, error) {
+	var result []config.BTCustomNodeCfg
+	for _, name := range extMap.GetNames() {
+		node, err := core.ExportCustomNode(name, extMap.GetNode(name)())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, node)
+	}
+	return result, nil
+}
+
+// Load a bundle after checking its checksums and signature, see bundle.LoadVerified
+func CreateProjectFromSignedBundle(path string, key ed25519.PublicKey, extMap *core.RegisterStructMaps) (*core.Project, error) {
+	result, err := bundle.LoadVerified(path, key)
+	if err != nil {
+		return nil, err
+	}
+	return CreateProjectFromConfig(result.Project, extMap), nil
+}
+
+func CreateBevTreeFromConfigWithOptions(config *config.BTTreeCfg, extMap *core.RegisterStructMaps, opts core.LoadOptions) *core.BehaviorTree {
+	baseMaps := createBaseFactoryMaps()
+	tree := core.NewBeTree()
+	tree.LoadWithOptions(config, baseMaps, extMap, opts)
+	return tree
+}
+
+// Load a tree replacing the nodes that cannot be created by placeholders
+func CreateBevTreeLenient(config *config.BTTreeCfg, extMap *core.RegisterStructMaps, mode core.PlaceholderMode) (*core.BehaviorTree, []core.Substitution) {
+	tree := CreateBevTreeFromConfigWithOptions(config, extMap, core.LoadOptions{Lenient: true, Placeholder: mode})
+	return tree, tree.GetSubstitutions()
+}
+
+// Load a project replacing the nodes that cannot be created by placeholders
+func CreateProjectLenient(config *config.BTProjectCfg, extMap *core.RegisterStructMaps, mode core.PlaceholderMode) (*core.Project, []core.Substitution) {
+	project := CreateProjectFromConfigWithOptions(config, extMap, core.LoadOptions{Lenient: true, Placeholder: mode})
+	return project, project.GetSubstitutions()
+}